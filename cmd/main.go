@@ -28,6 +28,8 @@ func main() {
 		log.Fatal(err)
 	}
 
+	defer func() { _ = storage.Close() }()
+
 	log.Println("Creating directory `/test`")
 	log.Println(storage.Mkdir(ctx, "/test", model.DirectoryPerm))
 
@@ -4,9 +4,12 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/ViBiOh/absto/pkg/breaker"
 	"github.com/ViBiOh/absto/pkg/filesystem"
 	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/absto/pkg/retry"
 	"github.com/ViBiOh/absto/pkg/s3"
 	"github.com/ViBiOh/absto/pkg/telemetry"
 	"github.com/ViBiOh/flags"
@@ -15,15 +18,38 @@ import (
 )
 
 type Config struct {
-	Directory    string
-	Endpoint     string
-	AccessKey    string
-	SecretAccess string
-	Bucket       string
-	Region       string
-	StorageClass string
-	UseSSL       bool
-	PartSize     uint64
+	Directory        string
+	SignatureURL     string
+	SignatureSecret  string
+	Symlinks         string
+	FileMode         string
+	DirMode          string
+	Group            string
+	Endpoint         string
+	AccessKey        string
+	SecretAccess     string
+	Bucket           string
+	Region           string
+	StorageClass     string
+	Directories      string
+	UseSSL           bool
+	Versioning       bool
+	Setgid           bool
+	SweepTemp        bool
+	BucketVersioning bool
+	PartSize         uint64
+	TokenSecret      string
+	NormalizeUnicode bool
+	RetryAttempts    uint
+	RetryMinBackoff  time.Duration
+	RetryMaxBackoff  time.Duration
+
+	BreakerThreshold        uint
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenRequests uint
+
+	// OnBreakerStateChange is notified of the circuit breaker transitions
+	OnBreakerStateChange func(from, to breaker.State)
 }
 
 func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
@@ -37,22 +63,53 @@ func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config
 	var config Config
 
 	flags.New("FileSystemDirectory", "Path to directory. Default is dynamic. `/data` on a server and Current Working Directory in a terminal.").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.Directory, defaultFS, overrides)
+	flags.New("FileSystemSignatureURL", "Base URL of the signed URLs handler").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.SignatureURL, "", overrides)
+	flags.New("FileSystemSignatureSecret", "Secret for signing URLs").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.SignatureSecret, "", overrides)
+	flags.New("FileSystemSymlinks", "Symlinks policy: follow those within the directory, refuse or expose them as links").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.Symlinks, "follow", overrides)
+	flags.New("FileSystemFileMode", "Mode of the created files, in octal").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.FileMode, "0600", overrides)
+	flags.New("FileSystemDirMode", "Mode of the created directories, in octal").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.DirMode, "0700", overrides)
+	flags.New("FileSystemGroup", "Group name or ID of the created files and directories, the one of the process if empty").Prefix(prefix).DocPrefix("filesystem").StringVar(fs, &config.Group, "", overrides)
+	flags.New("FileSystemSetgid", "Set the setgid bit on the created directories, their content inheriting their group").Prefix(prefix).DocPrefix("filesystem").BoolVar(fs, &config.Setgid, false, overrides)
+	flags.New("FileSystemSweepTemp", "Remove in the background the temporary files left over by a crash, walking the whole directory").Prefix(prefix).DocPrefix("filesystem").BoolVar(fs, &config.SweepTemp, false, overrides)
+	flags.New("FileSystemVersioning", "Keep prior versions of overwritten files").Prefix(prefix).DocPrefix("filesystem").BoolVar(fs, &config.Versioning, false, overrides)
 	flags.New("ObjectEndpoint", "Storage Object endpoint").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.Endpoint, "", overrides)
 	flags.New("ObjectAccessKey", "Storage Object Access Key").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.AccessKey, "", overrides)
 	flags.New("ObjectSecretAccess", "Storage Object Secret Access").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.SecretAccess, "", overrides)
 	flags.New("ObjectBucket", "Storage Object Bucket").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.Bucket, "", overrides)
 	flags.New("ObjectRegion", "Storage Object Region").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.Region, "", overrides)
 	flags.New("ObjectClass", "Storage Object Class").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.StorageClass, "", overrides)
+	flags.New("ObjectDirectories", "Directories representation: markers objects or implicit from the content").Prefix(prefix).DocPrefix("s3").StringVar(fs, &config.Directories, "markers", overrides)
 	flags.New("ObjectSSL", "Use SSL").Prefix(prefix).DocPrefix("s3").BoolVar(fs, &config.UseSSL, true, overrides)
+	flags.New("ObjectVersioning", "Bucket has versioning enabled").Prefix(prefix).DocPrefix("s3").BoolVar(fs, &config.BucketVersioning, false, overrides)
 	flags.New("PartSize", "PartSize configuration").Prefix(prefix).DocPrefix("s3").Uint64Var(fs, &config.PartSize, 5<<20, overrides)
 
+	flags.New("PathNormalizeUnicode", "Normalize names to the Unicode NFC form").Prefix(prefix).DocPrefix("path").BoolVar(fs, &config.NormalizeUnicode, false, overrides)
+
+	flags.New("ListTokenSecret", "Secret for signing the continuation tokens, random for the process if empty").Prefix(prefix).DocPrefix("list").StringVar(fs, &config.TokenSecret, "", overrides)
+
+	flags.New("RetryAttempts", "Maximum attempts of an operation on transient failures, 1 disables retries").Prefix(prefix).DocPrefix("retry").UintVar(fs, &config.RetryAttempts, 1, overrides)
+	flags.New("RetryMinBackoff", "Minimum backoff between two attempts").Prefix(prefix).DocPrefix("retry").DurationVar(fs, &config.RetryMinBackoff, retry.DefaultMinBackoff, overrides)
+	flags.New("RetryMaxBackoff", "Maximum backoff between two attempts").Prefix(prefix).DocPrefix("retry").DurationVar(fs, &config.RetryMaxBackoff, retry.DefaultMaxBackoff, overrides)
+
+	flags.New("BreakerThreshold", "Consecutive failures opening the circuit breaker, 0 disables it").Prefix(prefix).DocPrefix("breaker").UintVar(fs, &config.BreakerThreshold, 0, overrides)
+	flags.New("BreakerOpenTimeout", "Duration of the open circuit before probing the backend").Prefix(prefix).DocPrefix("breaker").DurationVar(fs, &config.BreakerOpenTimeout, breaker.DefaultOpenTimeout, overrides)
+	flags.New("BreakerHalfOpenRequests", "Successful probe requests closing the circuit").Prefix(prefix).DocPrefix("breaker").UintVar(fs, &config.BreakerHalfOpenRequests, breaker.DefaultHalfOpenRequests, overrides)
+
 	return &config
 }
 
 func New(config *Config, tracerProvider trace.TracerProvider) (storage model.Storage, err error) {
+	pathPolicy := model.DefaultPathPolicy
+	pathPolicy.NormalizeUnicode = config.NormalizeUnicode
+
 	endpoint := strings.TrimSpace(config.Endpoint)
 	if len(endpoint) != 0 {
-		var options []s3.ConfigOption
+		var directoryMode s3.DirectoryMode
+		if directoryMode, err = s3.ParseDirectoryMode(config.Directories); err != nil {
+			return nil, err
+		}
+
+		options := []s3.ConfigOption{s3.WithPathPolicy(pathPolicy), s3.WithDirectoryMode(directoryMode), s3.WithTokenSecret(config.TokenSecret)}
 
 		if region := strings.TrimSpace(config.Region); len(region) > 0 {
 			options = append(options, s3.WithRegion(region))
@@ -60,15 +117,62 @@ func New(config *Config, tracerProvider trace.TracerProvider) (storage model.Sto
 		if storageClass := strings.TrimSpace(config.StorageClass); len(storageClass) > 0 {
 			options = append(options, s3.WithStorageClass(storageClass))
 		}
+		if config.BucketVersioning {
+			options = append(options, s3.WithVersioning())
+		}
 
 		storage, err = s3.New(endpoint, strings.TrimSpace(config.AccessKey), config.SecretAccess, strings.TrimSpace(config.Bucket), config.UseSSL, config.PartSize, options...)
 	} else {
-		storage, err = filesystem.New(strings.TrimSpace(config.Directory))
+		var symlinkPolicy filesystem.SymlinkPolicy
+		if symlinkPolicy, err = filesystem.ParseSymlinkPolicy(config.Symlinks); err != nil {
+			return nil, err
+		}
+
+		var filePerm, dirPerm os.FileMode
+		if filePerm, err = filesystem.ParseMode(config.FileMode); err != nil {
+			return nil, err
+		}
+		if dirPerm, err = filesystem.ParseMode(config.DirMode); err != nil {
+			return nil, err
+		}
+
+		var gid int
+		if gid, err = filesystem.ParseGroup(config.Group); err != nil {
+			return nil, err
+		}
+
+		options := []filesystem.ConfigOption{filesystem.WithPathPolicy(pathPolicy), filesystem.WithSymlinkPolicy(symlinkPolicy), filesystem.WithPermissions(filePerm, dirPerm), filesystem.WithGroup(gid), filesystem.WithTokenSecret(config.TokenSecret)}
+
+		if config.Setgid {
+			options = append(options, filesystem.WithSetgid())
+		}
+
+		if config.SweepTemp {
+			options = append(options, filesystem.WithTempSweep())
+		}
+
+		if signatureURL := strings.TrimSpace(config.SignatureURL); len(signatureURL) > 0 {
+			options = append(options, filesystem.WithSignature(signatureURL, config.SignatureSecret))
+		}
+
+		if config.Versioning {
+			options = append(options, filesystem.WithVersioning())
+		}
+
+		storage, err = filesystem.New(strings.TrimSpace(config.Directory), options...)
 	}
 
 	if err != nil {
 		return storage, err
 	}
 
+	if config.RetryAttempts > 1 {
+		storage = retry.New(storage, retry.WithAttempts(config.RetryAttempts), retry.WithBackoff(config.RetryMinBackoff, config.RetryMaxBackoff))
+	}
+
+	if config.BreakerThreshold > 0 {
+		storage = breaker.New(storage, breaker.WithThreshold(config.BreakerThreshold), breaker.WithOpenTimeout(config.BreakerOpenTimeout), breaker.WithHalfOpenRequests(config.BreakerHalfOpenRequests), breaker.WithOnStateChange(config.OnBreakerStateChange))
+	}
+
 	return telemetry.New(storage, tracerProvider), nil
 }
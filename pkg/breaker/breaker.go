@@ -0,0 +1,348 @@
+package breaker
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	stateAttribute = "absto.breaker.state"
+	fromAttribute  = "absto.breaker.from"
+	stateEvent     = "absto.breaker.state_change"
+)
+
+var _ model.Storage = Service{}
+
+// Service fails fast with ErrOpen when the backend keeps failing, giving it time to recover. The circuit is shared
+// by the copies of the service.
+type Service struct {
+	storage model.Storage
+	circuit *circuit
+}
+
+func New(storage model.Storage, options ...ConfigOption) model.Storage {
+	config := Config{
+		threshold:        DefaultThreshold,
+		openTimeout:      DefaultOpenTimeout,
+		halfOpenRequests: DefaultHalfOpenRequests,
+	}
+
+	for _, option := range options {
+		config = option(config)
+	}
+
+	if config.threshold == 0 {
+		return storage
+	}
+
+	return Service{
+		storage: storage,
+		circuit: &circuit{
+			config: config,
+			now:    time.Now,
+		},
+	}
+}
+
+func (a Service) State() State {
+	return a.circuit.State()
+}
+
+func (a Service) observe(ctx context.Context, from, to State) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String(stateAttribute, to.String()))
+
+	if from == to {
+		return
+	}
+
+	span.AddEvent(stateEvent, trace.WithAttributes(attribute.String(fromAttribute, from.String()), attribute.String(stateAttribute, to.String())))
+
+	if a.circuit.config.onStateChange != nil {
+		a.circuit.config.onStateChange(from, to)
+	}
+}
+
+// admit checks that a request can be made, returning the state it is admitted in.
+func (a Service) admit(ctx context.Context) (State, error) {
+	from, to, ok := a.circuit.allow()
+	a.observe(ctx, from, to)
+
+	if !ok {
+		return to, ErrOpen
+	}
+
+	return to, nil
+}
+
+func (a Service) record(ctx context.Context, admitted State, err error) {
+	from, to := a.circuit.done(admitted, isFailure(a.storage.ConvertError(err)))
+	a.observe(ctx, from, to)
+}
+
+func call[T any](ctx context.Context, a Service, action func(context.Context) (T, error)) (T, error) {
+	admitted, err := a.admit(ctx)
+	if err != nil {
+		var output T
+		return output, err
+	}
+
+	output, err := action(ctx)
+	a.record(ctx, admitted, err)
+
+	return output, err
+}
+
+func callErr(ctx context.Context, a Service, action func(context.Context) error) error {
+	_, err := call(ctx, a, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, action(ctx)
+	})
+
+	return err
+}
+
+func (a Service) Enabled() bool {
+	return a.storage.Enabled()
+}
+
+func (a Service) Close() error {
+	return a.storage.Close()
+}
+
+func (a Service) Capabilities() model.Capabilities {
+	return a.storage.Capabilities()
+}
+
+func (a Service) Name() string {
+	return a.storage.Name()
+}
+
+func (a Service) WithIgnoreFn(ignoreFn func(model.Item) bool) model.Storage {
+	return Service{
+		storage: a.storage.WithIgnoreFn(ignoreFn),
+		circuit: a.circuit,
+	}
+}
+
+func (a Service) Path(name string) string {
+	return a.storage.Path(name)
+}
+
+func (a Service) Stat(ctx context.Context, name string) (model.Item, error) {
+	return call(ctx, a, func(ctx context.Context) (model.Item, error) {
+		return a.storage.Stat(ctx, name)
+	})
+}
+
+func (a Service) List(ctx context.Context, name string) ([]model.Item, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.Item, error) {
+		return a.storage.List(ctx, name)
+	})
+}
+
+func (a Service) ListPage(ctx context.Context, name string, opts model.ListOpts) (model.Page, error) {
+	return call(ctx, a, func(ctx context.Context) (model.Page, error) {
+		return a.storage.ListPage(ctx, name, opts)
+	})
+}
+
+func (a Service) Glob(ctx context.Context, pattern string) ([]model.Item, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.Item, error) {
+		return a.storage.Glob(ctx, pattern)
+	})
+}
+
+func (a Service) Usage(ctx context.Context, name string) (model.Usage, error) {
+	return call(ctx, a, func(ctx context.Context) (model.Usage, error) {
+		return a.storage.Usage(ctx, name)
+	})
+}
+
+func (a Service) GetTags(ctx context.Context, name string) (map[string]string, error) {
+	return call(ctx, a, func(ctx context.Context) (map[string]string, error) {
+		return a.storage.GetTags(ctx, name)
+	})
+}
+
+func (a Service) SetTags(ctx context.Context, name string, tags map[string]string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.SetTags(ctx, name, tags)
+	})
+}
+
+func (a Service) DeleteTags(ctx context.Context, name string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.DeleteTags(ctx, name)
+	})
+}
+
+func (a Service) ListVersions(ctx context.Context, name string) ([]model.Version, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.Version, error) {
+		return a.storage.ListVersions(ctx, name)
+	})
+}
+
+func (a Service) ReadVersion(ctx context.Context, name, versionID string) (model.ReadAtSeekCloser, error) {
+	return call(ctx, a, func(ctx context.Context) (model.ReadAtSeekCloser, error) {
+		return a.storage.ReadVersion(ctx, name, versionID)
+	})
+}
+
+func (a Service) RestoreVersion(ctx context.Context, name, versionID string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.RestoreVersion(ctx, name, versionID)
+	})
+}
+
+func (a Service) CreateUpload(ctx context.Context, name string, opts model.WriteOpts) (string, error) {
+	return call(ctx, a, func(ctx context.Context) (string, error) {
+		return a.storage.CreateUpload(ctx, name, opts)
+	})
+}
+
+func (a Service) UploadPart(ctx context.Context, name, uploadID string, partNumber int, reader io.Reader, size int64) (model.Part, error) {
+	return call(ctx, a, func(ctx context.Context) (model.Part, error) {
+		return a.storage.UploadPart(ctx, name, uploadID, partNumber, reader, size)
+	})
+}
+
+func (a Service) ListParts(ctx context.Context, name, uploadID string) ([]model.Part, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.Part, error) {
+		return a.storage.ListParts(ctx, name, uploadID)
+	})
+}
+
+func (a Service) CompleteUpload(ctx context.Context, name, uploadID string, parts []model.Part) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.CompleteUpload(ctx, name, uploadID, parts)
+	})
+}
+
+func (a Service) AbortUpload(ctx context.Context, name, uploadID string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.AbortUpload(ctx, name, uploadID)
+	})
+}
+
+func (a Service) ListUploads(ctx context.Context, name string) ([]model.Upload, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.Upload, error) {
+		return a.storage.ListUploads(ctx, name)
+	})
+}
+
+func (a Service) WriteTo(ctx context.Context, name string, reader io.Reader, opts model.WriteOpts) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.WriteTo(ctx, name, reader, opts)
+	})
+}
+
+func (a Service) ReadFrom(ctx context.Context, name string) (model.ReadAtSeekCloser, error) {
+	return call(ctx, a, func(ctx context.Context) (model.ReadAtSeekCloser, error) {
+		return a.storage.ReadFrom(ctx, name)
+	})
+}
+
+func (a Service) UpdateDate(ctx context.Context, name string, date time.Time) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.UpdateDate(ctx, name, date)
+	})
+}
+
+func (a Service) Walk(ctx context.Context, name string, walkFn func(model.Item) error) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.Walk(ctx, name, walkFn)
+	})
+}
+
+func (a Service) All(ctx context.Context, name string, opts model.WalkOpts) iter.Seq2[model.Item, error] {
+	return a.seq(ctx, a.storage.All(ctx, name, opts))
+}
+
+func (a Service) ListSeq(ctx context.Context, name string) iter.Seq2[model.Item, error] {
+	return a.seq(ctx, a.storage.ListSeq(ctx, name))
+}
+
+func (a Service) seq(ctx context.Context, seq iter.Seq2[model.Item, error]) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		admitted, err := a.admit(ctx)
+		if err != nil {
+			yield(model.Item{}, err)
+			return
+		}
+
+		var failure error
+
+		defer func() { a.record(ctx, admitted, failure) }()
+
+		for item, err := range seq {
+			if err != nil {
+				failure = err
+			}
+
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// Watch is refused while the circuit is open. Its outcome is only known once the channel is closed, so it doesn't
+// hold a probe slot nor count as a probe.
+func (a Service) Watch(ctx context.Context, name string) <-chan model.Event {
+	admitted, err := a.admit(ctx)
+	if err != nil {
+		output := make(chan model.Event, 1)
+		output <- model.Event{Err: err}
+		close(output)
+
+		return output
+	}
+
+	a.circuit.release(admitted)
+
+	return a.storage.Watch(ctx, name)
+}
+
+func (a Service) SignedURL(ctx context.Context, name, method string, expiry time.Duration) (string, error) {
+	return a.storage.SignedURL(ctx, name, method, expiry)
+}
+
+func (a Service) SignedHandler() http.Handler {
+	return a.storage.SignedHandler()
+}
+
+func (a Service) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.Mkdir(ctx, name, perm)
+	})
+}
+
+func (a Service) Rename(ctx context.Context, oldName, newName string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.Rename(ctx, oldName, newName)
+	})
+}
+
+func (a Service) RemoveAll(ctx context.Context, name string) error {
+	return callErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.RemoveAll(ctx, name)
+	})
+}
+
+func (a Service) RemoveMany(ctx context.Context, names []string) ([]model.RemoveResult, error) {
+	return call(ctx, a, func(ctx context.Context) ([]model.RemoveResult, error) {
+		return a.storage.RemoveMany(ctx, names)
+	})
+}
+
+func (a Service) ConvertError(err error) error {
+	return a.storage.ConvertError(err)
+}
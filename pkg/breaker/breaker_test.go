@@ -0,0 +1,181 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var errTransient = model.WrapError(errors.New("slow down"), model.ErrUnavailable)
+
+type fakeStorage struct {
+	model.Storage
+	err *error
+}
+
+func (f fakeStorage) ConvertError(err error) error {
+	return err
+}
+
+func (f fakeStorage) Stat(_ context.Context, name string) (model.Item, error) {
+	return model.Item{Pathname: name}, *f.err
+}
+
+func (f fakeStorage) Watch(_ context.Context, _ string) <-chan model.Event {
+	output := make(chan model.Event)
+	close(output)
+
+	return output
+}
+
+type transition struct {
+	from State
+	to   State
+}
+
+func TestCycle(t *testing.T) {
+	t.Parallel()
+
+	var failure error
+
+	now := time.Now()
+	var transitions []transition
+
+	storage := New(fakeStorage{err: &failure}, WithThreshold(2), WithOpenTimeout(time.Minute), WithHalfOpenRequests(1), WithOnStateChange(func(from, to State) {
+		transitions = append(transitions, transition{from, to})
+	})).(Service)
+	storage.circuit.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	failure = errTransient
+
+	for range 2 {
+		if _, err := storage.Stat(ctx, "/file"); !errors.Is(err, errTransient) {
+			t.Fatalf("Stat() = %v, want %v", err, errTransient)
+		}
+	}
+
+	if got := storage.State(); got != Open {
+		t.Fatalf("State() = %s, want %s", got, Open)
+	}
+
+	if _, err := storage.Stat(ctx, "/file"); !errors.Is(err, ErrOpen) || !model.IsUnavailable(err) {
+		t.Fatalf("Stat() = %v, want %v", err, ErrOpen)
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, err := storage.Stat(ctx, "/file"); !errors.Is(err, errTransient) {
+		t.Fatalf("Stat() = %v, want %v", err, errTransient)
+	}
+
+	if got := storage.State(); got != Open {
+		t.Fatalf("State() = %s, want %s", got, Open)
+	}
+
+	now = now.Add(time.Minute)
+	failure = nil
+
+	if _, err := storage.Stat(ctx, "/file"); err != nil {
+		t.Fatalf("Stat() = %v", err)
+	}
+
+	if got := storage.State(); got != Closed {
+		t.Fatalf("State() = %s, want %s", got, Closed)
+	}
+
+	want := []transition{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Open}, {Open, HalfOpen}, {HalfOpen, Closed}}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+
+	for index, got := range transitions {
+		if got != want[index] {
+			t.Errorf("transitions[%d] = %v, want %v", index, got, want[index])
+		}
+	}
+}
+
+func TestWatchHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	failure := errTransient
+
+	now := time.Now()
+
+	storage := New(fakeStorage{err: &failure}, WithThreshold(1), WithOpenTimeout(time.Minute), WithHalfOpenRequests(1)).(Service)
+	storage.circuit.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	if _, err := storage.Stat(ctx, "/file"); !errors.Is(err, errTransient) {
+		t.Fatalf("Stat() = %v, want %v", err, errTransient)
+	}
+
+	if event, ok := <-storage.Watch(ctx, "/"); !ok || !errors.Is(event.Err, ErrOpen) {
+		t.Fatalf("Watch() = %v, want %v", event.Err, ErrOpen)
+	}
+
+	now = now.Add(time.Minute)
+	failure = nil
+
+	if event, ok := <-storage.Watch(ctx, "/"); ok {
+		t.Fatalf("Watch() = %v, want closed channel", event)
+	}
+
+	if got := storage.State(); got != HalfOpen {
+		t.Fatalf("State() = %s, want %s", got, HalfOpen)
+	}
+
+	if _, err := storage.Stat(ctx, "/file"); err != nil {
+		t.Fatalf("Stat() = %v", err)
+	}
+
+	if got := storage.State(); got != Closed {
+		t.Fatalf("State() = %s, want %s", got, Closed)
+	}
+}
+
+func TestIsFailure(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil": {
+			nil,
+			false,
+		},
+		"canceled": {
+			context.Canceled,
+			false,
+		},
+		"deadline": {
+			context.DeadlineExceeded,
+			true,
+		},
+		"not exist": {
+			model.ErrNotExist(errors.New("missing")),
+			false,
+		},
+		"unavailable": {
+			errTransient,
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isFailure(testCase.err); got != testCase.want {
+				t.Errorf("isFailure() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/absto/pkg/retry"
+)
+
+const (
+	DefaultThreshold        = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+var ErrOpen = fmt.Errorf("circuit breaker is open: %w", model.ErrUnavailable)
+
+type State uint8
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	onStateChange    func(from, to State)
+	threshold        uint
+	halfOpenRequests uint
+	openTimeout      time.Duration
+}
+
+type ConfigOption func(Config) Config
+
+// WithThreshold sets the number of consecutive failures opening the circuit.
+func WithThreshold(threshold uint) ConfigOption {
+	return func(instance Config) Config {
+		instance.threshold = threshold
+
+		return instance
+	}
+}
+
+// WithOpenTimeout sets the duration of the open state before letting probe requests through.
+func WithOpenTimeout(openTimeout time.Duration) ConfigOption {
+	return func(instance Config) Config {
+		instance.openTimeout = openTimeout
+
+		return instance
+	}
+}
+
+// WithHalfOpenRequests sets the number of successful probe requests closing the circuit.
+func WithHalfOpenRequests(halfOpenRequests uint) ConfigOption {
+	return func(instance Config) Config {
+		instance.halfOpenRequests = max(halfOpenRequests, 1)
+
+		return instance
+	}
+}
+
+func WithOnStateChange(onStateChange func(from, to State)) ConfigOption {
+	return func(instance Config) Config {
+		instance.onStateChange = onStateChange
+
+		return instance
+	}
+}
+
+type circuit struct {
+	openedAt  time.Time
+	now       func() time.Time
+	config    Config
+	mutex     sync.Mutex
+	failures  uint
+	probes    uint
+	successes uint
+	state     State
+}
+
+func (c *circuit) State() State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.state
+}
+
+// allow reports whether a request can be made, moving to the half-open state once the open timeout is elapsed.
+// It returns the state before and after the check, the request being admitted in the latter.
+func (c *circuit) allow() (State, State, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	from := c.state
+
+	if c.state == Open && c.now().Sub(c.openedAt) >= c.config.openTimeout {
+		c.state = HalfOpen
+		c.probes = 0
+		c.successes = 0
+	}
+
+	switch c.state {
+	case Open:
+		return from, c.state, false
+	case HalfOpen:
+		if c.probes >= c.config.halfOpenRequests {
+			return from, c.state, false
+		}
+
+		c.probes++
+	}
+
+	return from, c.state, true
+}
+
+// done records the outcome of a request admitted in the given state, the outcome being ignored if the state has changed since.
+func (c *circuit) done(admitted State, failure bool) (State, State) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	from := c.state
+
+	if admitted != c.state {
+		return from, c.state
+	}
+
+	switch c.state {
+	case Closed:
+		if !failure {
+			c.failures = 0
+		} else if c.failures++; c.failures >= c.config.threshold {
+			c.open()
+		}
+
+	case HalfOpen:
+		c.probes--
+
+		if failure {
+			c.open()
+		} else if c.successes++; c.successes >= c.config.halfOpenRequests {
+			c.state = Closed
+			c.failures = 0
+		}
+	}
+
+	return from, c.state
+}
+
+// release gives back the probe slot of a request admitted in the given state, without recording an outcome.
+func (c *circuit) release(admitted State) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if admitted == HalfOpen && c.state == HalfOpen {
+		c.probes--
+	}
+}
+
+func (c *circuit) open() {
+	c.state = Open
+	c.openedAt = c.now()
+	c.failures = 0
+}
+
+// isFailure reports whether the error shows a struggling backend. A deadline is counted because a browned out
+// backend makes the requests hang until their timeout.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) || retry.Retryable(err)
+}
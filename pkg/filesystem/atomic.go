@@ -0,0 +1,206 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// The content is written to a hidden file next to its destination then renamed into place, the readers never seeing
+// a partial content. The creations are told apart from the updates by their prefix, the watch events being sent after the rename.
+const (
+	tempPrefix       = ".absto-tmp-"
+	tempCreatePrefix = tempPrefix + "create-"
+	tempUpdatePrefix = tempPrefix + "update-"
+	tempPartPrefix   = tempPrefix + "part-"
+
+	// tempMaxAge is the age after which a temporary file is considered as left over by a crash.
+	tempMaxAge = time.Hour
+)
+
+func isTempName(name string) bool {
+	return strings.HasPrefix(path.Base(name), tempPrefix)
+}
+
+// hasTempSegment reports whether a segment of the name is a temporary name, hidden from the listings and swept.
+func hasTempSegment(name string) bool {
+	for segment := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(segment, tempPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replace writes the content to a temporary file then renames it into place. A symlink at the destination is
+// replaced by the file.
+func (a Service) replace(name string, reader io.Reader, opts model.WriteOpts) error {
+	if err := a.checkSymlinks(name); err != nil {
+		return err
+	}
+
+	info, err := a.stat(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return a.ConvertError(err)
+	}
+
+	exists := err == nil
+
+	if exists && info.IsDir() {
+		return a.ConvertError(&fs.PathError{Op: "open", Path: rootName(name), Err: model.ErrIsDirectory})
+	}
+
+	if exists && opts.Mode == model.CreateExclusive {
+		return a.ConvertError(&fs.PathError{Op: "open", Path: rootName(name), Err: fs.ErrExist})
+	}
+
+	var archived string
+
+	if exists && a.versioning && opts.Mode == model.Overwrite {
+		if archived, err = a.archiveVersion(name); err != nil {
+			return err
+		}
+	}
+
+	// A replaced file keeps its mode, the configured one being for the created files
+	prefix, perm := tempUpdatePrefix, a.filePerm
+	if exists {
+		perm = info.Mode().Perm()
+	} else {
+		prefix = tempCreatePrefix
+	}
+
+	directory := path.Dir(rootName(name))
+
+	temp, tempName, err := a.createTemp(directory, prefix, perm)
+	if err != nil {
+		return errors.Join(fmt.Errorf("create temporary file: %w", err), a.removeArchived(archived))
+	}
+
+	err = a.copyContent(tempName, temp, reader, opts)
+
+	if err = errors.Join(err, temp.Close()); err == nil {
+		if opts.Mode == model.CreateExclusive {
+			err = a.renameExclusive(tempName, rootName(name))
+		} else {
+			err = a.root.Rename(tempName, rootName(name))
+		}
+
+		if err != nil {
+			err = a.ConvertError(&fs.PathError{Op: "rename", Path: rootName(name), Err: err})
+		}
+	}
+
+	if err != nil {
+		return errors.Join(err, a.removeTemp(tempName), a.removeArchived(archived))
+	}
+
+	if err = a.moveSidecar(tempName, rootName(name)); err != nil {
+		return err
+	}
+
+	if opts.Durability == model.DurabilityFull {
+		return a.syncDirectory(directory)
+	}
+
+	return nil
+}
+
+// copyContent writes the content and the attributes of the named file, flushing it according to the durability.
+func (a Service) copyContent(name string, file *os.File, reader io.Reader, opts model.WriteOpts) error {
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+
+	hasher := sha256.New()
+
+	if _, err := io.CopyBuffer(io.MultiWriter(file, hasher), reader, *buf); err != nil {
+		return a.ConvertError(err)
+	}
+
+	var checksum string
+	if opts.Mode != model.Append {
+		checksum = hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	if err := a.writeAttributes(name, file, opts, checksum); err != nil {
+		return err
+	}
+
+	if opts.Durability == model.DurabilityNone {
+		return nil
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync: %w", a.ConvertError(err))
+	}
+
+	return nil
+}
+
+// renameExclusive renames the file only if the destination doesn't exist, a hard link being used when the
+// filesystem can't do it in a single call.
+func (a Service) renameExclusive(oldName, newName string) error {
+	err := renameNoReplace(a.root, path.Dir(newName), path.Base(oldName), path.Base(newName))
+	if !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	if err = a.root.Link(oldName, newName); err != nil {
+		return err
+	}
+
+	return a.removeTemp(oldName)
+}
+
+func (a Service) removeTemp(name string) error {
+	if err := a.root.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove temporary file: %w", a.ConvertError(err))
+	}
+
+	return a.removeSidecar(name)
+}
+
+func (a Service) removeArchived(archived string) error {
+	if len(archived) == 0 {
+		return nil
+	}
+
+	if err := a.root.Remove(archived); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove archived version: %w", a.ConvertError(err))
+	}
+
+	return a.removeSidecar(archived)
+}
+
+// sweepTemp removes the temporary files left over by a crash, the recent ones being possibly in use by another instance.
+func (a Service) sweepTemp(now time.Time) {
+	_ = fs.WalkDir(a.root.FS(), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || !isTempName(name) {
+			return nil
+		}
+
+		if info, err := entry.Info(); err == nil && now.Sub(info.ModTime()) > tempMaxAge {
+			_ = a.root.Remove(name)
+		}
+
+		return nil
+	})
+}
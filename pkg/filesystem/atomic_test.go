@@ -0,0 +1,177 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var errBrokenReader = errors.New("broken reader")
+
+type brokenReader struct{}
+
+func (brokenReader) Read(p []byte) (int, error) {
+	return copy(p, "partial"), errBrokenReader
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		reader  io.Reader
+		opts    model.WriteOpts
+		want    string
+		wantErr error
+	}{
+		"full": {
+			strings.NewReader("second"),
+			model.WriteOpts{},
+			"second",
+			nil,
+		},
+		"none": {
+			strings.NewReader("second"),
+			model.WriteOpts{Durability: model.DurabilityNone},
+			"second",
+			nil,
+		},
+		"broken": {
+			brokenReader{},
+			model.WriteOpts{},
+			"first",
+			errBrokenReader,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t, "/dir/")
+			ctx := context.Background()
+
+			if err := instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("first"), model.WriteOpts{}); err != nil {
+				t.Fatal(err)
+			}
+
+			previous, err := instance.ReadFrom(ctx, "/dir/file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer func() { _ = previous.Close() }()
+
+			if gotErr := instance.WriteTo(ctx, "/dir/file.txt", tc.reader, tc.opts); !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("WriteTo() = `%v`, want `%v`", gotErr, tc.wantErr)
+			}
+
+			if content, err := io.ReadAll(previous); err != nil || string(content) != "first" {
+				t.Errorf("previous reader = (`%s`, `%v`), want `first`", content, err)
+			}
+
+			content, err := instance.root.ReadFile("dir/file.txt")
+			if err != nil || string(content) != tc.want {
+				t.Errorf("content = (`%s`, `%v`), want `%s`", content, err, tc.want)
+			}
+
+			entries, err := fs.ReadDir(instance.root.FS(), "dir")
+			if err != nil || len(entries) != 1 {
+				t.Errorf("directory has %d entries, want 1", len(entries))
+			}
+		})
+	}
+}
+
+func TestReplaceMode(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/dir/")
+	ctx := context.Background()
+
+	if err := instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("first"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := instance.root.Chmod("dir/file.txt", 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("second"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := instance.root.Stat("dir/file.txt"); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("Stat() = (%v, `%v`), want the mode kept", info.Mode(), err)
+	}
+
+	if err := instance.WriteTo(ctx, "/dir/other.txt", strings.NewReader("first"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := instance.root.Stat("dir/other.txt"); err != nil || info.Mode().Perm() != instance.filePerm {
+		t.Errorf("Stat() = (%v, `%v`), want the configured mode", info.Mode(), err)
+	}
+}
+
+func TestRenameExclusive(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/file.txt")
+
+	temp, tempName, err := instance.createTemp(".", tempCreatePrefix, model.RegularFilePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = temp.Close()
+
+	if err = instance.renameExclusive(tempName, "file.txt"); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("renameExclusive() = `%v`, want `%v`", err, fs.ErrExist)
+	}
+
+	if err = instance.renameExclusive(tempName, "created.txt"); err != nil {
+		t.Errorf("renameExclusive() = `%v`", err)
+	}
+
+	if _, err = instance.root.Stat(tempName); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("temporary file = `%v`, want removed", err)
+	}
+}
+
+func TestSweepTemp(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/dir/")
+	now := time.Now()
+
+	for name, date := range map[string]time.Time{
+		"dir/" + tempUpdatePrefix + "old":    now.Add(-2 * tempMaxAge),
+		"dir/" + tempUpdatePrefix + "recent": now,
+	} {
+		if err := instance.root.WriteFile(name, []byte("partial"), model.RegularFilePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := instance.root.Chtimes(name, date, date); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := instance.List(context.Background(), "/dir/")
+	if err != nil || len(items) != 0 {
+		t.Errorf("List() = (%d items, `%v`), want none", len(items), err)
+	}
+
+	instance.sweepTemp(now)
+
+	entries, err := fs.ReadDir(instance.root.FS(), "dir")
+	if err != nil || len(entries) != 1 || entries[0].Name() != tempUpdatePrefix+"recent" {
+		t.Errorf("sweepTemp() kept %v, want the recent file", entries)
+	}
+}
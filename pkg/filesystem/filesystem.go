@@ -6,19 +6,31 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"iter"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/ViBiOh/absto/pkg/model"
 )
 
-const Name = "filesystem"
+const (
+	Name = "filesystem"
 
-var _ model.Storage = Service{}
+	listBatchSize = 256
+)
+
+var (
+	_ model.Storage = Service{}
+
+	errStopIteration = errors.New("stop iteration")
+)
 
 var bufferPool = sync.Pool{
 	New: func() any {
@@ -27,19 +39,153 @@ var bufferPool = sync.Pool{
 	},
 }
 
+type Config struct {
+	signatureURL    string
+	signatureSecret string
+	tokenSecret     string
+	pathPolicy      model.PathPolicy
+	symlinkPolicy   SymlinkPolicy
+	filePerm        os.FileMode
+	dirPerm         os.FileMode
+	gid             int
+	setgid          bool
+	sidecar         bool
+	versioning      bool
+	sweepTemp       bool
+}
+
+type ConfigOption func(Config) Config
+
+func WithSignature(baseURL, secret string) ConfigOption {
+	return func(instance Config) Config {
+		instance.signatureURL = baseURL
+		instance.signatureSecret = secret
+
+		return instance
+	}
+}
+
+// WithTokenSecret sets the secret signing the continuation tokens, a random one for the process by default.
+func WithTokenSecret(secret string) ConfigOption {
+	return func(instance Config) Config {
+		instance.tokenSecret = secret
+
+		return instance
+	}
+}
+
+// WithPathPolicy sets the validation and normalization of the names, model.DefaultPathPolicy by default.
+func WithPathPolicy(pathPolicy model.PathPolicy) ConfigOption {
+	return func(instance Config) Config {
+		instance.pathPolicy = pathPolicy
+
+		return instance
+	}
+}
+
+// WithSymlinkPolicy sets the handling of the symlinks, SymlinkFollow by default.
+func WithSymlinkPolicy(symlinkPolicy SymlinkPolicy) ConfigOption {
+	return func(instance Config) Config {
+		instance.symlinkPolicy = symlinkPolicy
+
+		return instance
+	}
+}
+
+// WithPermissions sets the mode of the created files and directories, model.RegularFilePerm and model.DirectoryPerm by default.
+func WithPermissions(filePerm, dirPerm os.FileMode) ConfigOption {
+	return func(instance Config) Config {
+		instance.filePerm = filePerm
+		instance.dirPerm = dirPerm
+
+		return instance
+	}
+}
+
+// WithGroup sets the group of the created files and directories, the process needing to be a member of it.
+func WithGroup(gid int) ConfigOption {
+	return func(instance Config) Config {
+		instance.gid = gid
+
+		return instance
+	}
+}
+
+// WithSetgid sets the setgid bit on the created directories, their content inheriting their group.
+func WithSetgid() ConfigOption {
+	return func(instance Config) Config {
+		instance.setgid = true
+
+		return instance
+	}
+}
+
+// WithSidecarMetadata stores the metadata and the tags in sidecar files instead of the extended attributes, the
+// sidecar files being used anyway when the filesystem doesn't support them.
+func WithSidecarMetadata() ConfigOption {
+	return func(instance Config) Config {
+		instance.sidecar = true
+
+		return instance
+	}
+}
+
+// WithTempSweep removes the temporary files left over by a crash in the background once opened, the whole
+// directory being walked.
+func WithTempSweep() ConfigOption {
+	return func(instance Config) Config {
+		instance.sweepTemp = true
+
+		return instance
+	}
+}
+
+// WithVersioning keeps the prior versions of the overwritten files.
+func WithVersioning() ConfigOption {
+	return func(instance Config) Config {
+		instance.versioning = true
+
+		return instance
+	}
+}
+
+// Service operates on the files through an os.Root, a name or a symlink never giving access outside the root directory.
 type Service struct {
 	ignoreFn      func(model.Item) bool
+	signatureURL  *url.URL
+	root          *os.Root
 	rootDirectory string
 	rootDirname   string
+	signatureKey  []byte
+	tokenKey      []byte
+	pathPolicy    model.PathPolicy
+	symlinkPolicy SymlinkPolicy
+	filePerm      os.FileMode
+	dirPerm       os.FileMode
+	gid           int
+	setgid        bool
+	sidecar       bool
+	versioning    bool
 }
 
-func New(directory string) (Service, error) {
+func New(directory string, options ...ConfigOption) (Service, error) {
 	rootDirectory := strings.TrimSuffix(directory, "/")
 
 	if len(rootDirectory) == 0 {
 		return Service{}, nil
 	}
 
+	config := Config{
+		pathPolicy: model.DefaultPathPolicy,
+		filePerm:   model.RegularFilePerm,
+		dirPerm:    model.DirectoryPerm,
+		gid:        noGroup,
+	}
+
+	for _, option := range options {
+		config = option(config)
+	}
+
 	info, err := os.Stat(rootDirectory)
 	if err != nil {
 		return Service{}, Service{}.ConvertError(err)
@@ -49,22 +195,79 @@ func New(directory string) (Service, error) {
 		return Service{}, fmt.Errorf("path %s is not a directory", rootDirectory)
 	}
 
-	return Service{
+	root, err := os.OpenRoot(rootDirectory)
+	if err != nil {
+		return Service{}, fmt.Errorf("open root: %w", Service{}.ConvertError(err))
+	}
+
+	service := Service{
+		root:          root,
 		rootDirectory: rootDirectory,
 		rootDirname:   info.Name(),
-	}, nil
+		tokenKey:      model.TokenKey(config.tokenSecret),
+		pathPolicy:    config.pathPolicy,
+		symlinkPolicy: config.symlinkPolicy,
+		filePerm:      config.filePerm,
+		dirPerm:       config.dirPerm,
+		gid:           config.gid,
+		setgid:        config.setgid,
+		versioning:    config.versioning,
+	}
+
+	service.sidecar = config.sidecar || !service.supportsXattr()
+	service.ignoreFn = service.hideInternal(nil)
+
+	if len(config.signatureURL) != 0 {
+		if len(config.signatureSecret) == 0 {
+			return Service{}, errors.Join(errors.New("signature secret is required with a signature url"), root.Close())
+		}
+
+		service.signatureURL, err = url.Parse(strings.TrimSuffix(config.signatureURL, "/"))
+		if err != nil {
+			return Service{}, errors.Join(fmt.Errorf("parse signature url: %w", err), root.Close())
+		}
+
+		service.signatureKey = []byte(config.signatureSecret)
+	}
+
+	if config.sweepTemp {
+		go service.sweepTemp(time.Now())
+	}
+
+	return service, nil
 }
 
 func (a Service) Enabled() bool {
 	return len(a.rootDirectory) != 0
 }
 
+// Close releases the root directory, the service and its copies being unusable afterward.
+func (a Service) Close() error {
+	if a.root == nil {
+		return nil
+	}
+
+	return a.root.Close()
+}
+
+func (a Service) Capabilities() model.Capabilities {
+	return model.Capabilities{
+		AtomicRename:   true,
+		PreservesMtime: true,
+		RangeReads:     true,
+		ServerSideCopy: true,
+		Versioning:     a.versioning,
+		NativeWatch:    nativeWatch,
+		SignedURL:      a.signatureURL != nil,
+	}
+}
+
 func (a Service) Name() string {
 	return Name
 }
 
 func (a Service) WithIgnoreFn(ignoreFn func(model.Item) bool) model.Storage {
-	a.ignoreFn = ignoreFn
+	a.ignoreFn = a.hideInternal(ignoreFn)
 
 	return a
 }
@@ -78,72 +281,238 @@ func (a Service) Path(name string) string {
 }
 
 func (a Service) Stat(_ context.Context, name string) (model.Item, error) {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return model.Item{}, err
 	}
 
-	fullpath := a.Path(name)
-
-	info, err := os.Stat(fullpath)
+	info, err := a.stat(name)
 	if err != nil {
 		return model.Item{}, a.ConvertError(err)
 	}
 
-	return convertToItem(a.getRelativePath(fullpath), info), nil
+	return a.withMetadata(name, convertToItem(pathname(name), info))
 }
 
 func (a Service) List(_ context.Context, name string) ([]model.Item, error) {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return nil, err
 	}
 
-	fullpath := a.Path(name)
-
-	files, err := os.ReadDir(fullpath)
+	files, err := a.readDir(name)
 	if err != nil {
-		return nil, a.ConvertError(err)
+		return nil, err
 	}
 
 	var items []model.Item
-	for _, file := range files {
-		fileInfo, err := file.Info()
+	for item, err := range a.entries(name, files, "", false) {
 		if err != nil {
-			return nil, fmt.Errorf("read file metadata: %w", err)
+			return nil, err
 		}
 
-		item := convertToItem(a.getRelativePath(path.Join(fullpath, file.Name())), fileInfo)
-		if a.ignoreFn != nil && a.ignoreFn(item) {
-			continue
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (a Service) ListPage(_ context.Context, name string, opts model.ListOpts) (model.Page, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	prefix := pathname(name)
+
+	cursor, err := opts.Cursor(a.tokenKey, prefix)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	return model.CollectPage(a.pageEntries(name, cursor, opts.PageSize()+1, opts.WithMetadata), a.tokenKey, prefix, cursor, opts.PageSize())
+}
+
+// pageEntries reads the items of the directory after the cursor in lexical order, the directory being read again
+// when some of the next entries are hidden.
+func (a Service) pageEntries(dirname, cursor string, count int, withMetadata bool) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		for {
+			files, err := a.nextEntries(dirname, cursor, count)
+			if err != nil {
+				yield(model.Item{}, err)
+				return
+			}
+
+			for item, err := range a.entries(dirname, files, cursor, withMetadata) {
+				if !yield(item, err) || err != nil {
+					return
+				}
+			}
+
+			if len(files) < count {
+				return
+			}
+
+			cursor = pathname(path.Join(dirname, files[len(files)-1].Name()))
 		}
+	}
+}
 
-		items = append(items, item)
+// nextEntries reads the directory by chunks, keeping in memory only the first entries after the cursor in lexical order.
+func (a Service) nextEntries(dirname, cursor string, count int) ([]fs.DirEntry, error) {
+	directory, err := a.open(dirname, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = directory.Close() }()
+
+	var entries []fs.DirEntry
+
+	for {
+		chunk, err := directory.ReadDir(listBatchSize)
+
+		for _, entry := range chunk {
+			name := entry.Name()
+
+			if len(cursor) != 0 && pathname(path.Join(dirname, name)) <= cursor {
+				continue
+			}
+
+			if len(entries) == count && name >= entries[count-1].Name() {
+				continue
+			}
+
+			index, _ := slices.BinarySearchFunc(entries, name, func(entry fs.DirEntry, name string) int {
+				return strings.Compare(entry.Name(), name)
+			})
+
+			if entries = slices.Insert(entries, index, entry); len(entries) > count {
+				entries = entries[:count]
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, a.ConvertError(err)
+		}
+	}
+}
+
+func (a Service) Glob(ctx context.Context, pattern string) ([]model.Item, error) {
+	matcher, err := model.NewPattern(pattern, a.pathPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []model.Item
+
+	err = a.Walk(ctx, matcher.Dir(), func(item model.Item) error {
+		if matcher.Match(item.Pathname) {
+			items = append(items, item)
+		}
+
+		if item.IsDir() && !matcher.MatchDir(item.Pathname) {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil && !model.IsNotExist(err) {
+		return nil, err
 	}
 
 	return items, nil
 }
 
-func (a Service) WriteTo(_ context.Context, name string, reader io.Reader, _ model.WriteOpts) error {
-	if err := model.ValidPath(name); err != nil {
+func (a Service) entries(dirname string, files []fs.DirEntry, cursor string, withMetadata bool) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		for _, file := range files {
+			if len(cursor) != 0 && pathname(path.Join(dirname, file.Name())) <= cursor {
+				continue
+			}
+
+			item, ok, err := a.readEntry(dirname, file, withMetadata)
+			if err != nil {
+				yield(model.Item{}, err)
+				return
+			}
+
+			if !ok || (a.ignoreFn != nil && a.ignoreFn(item)) {
+				continue
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (a Service) WriteTo(_ context.Context, name string, reader io.Reader, opts model.WriteOpts) error {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := model.ValidTags(opts.Tags); err != nil {
 		return err
 	}
 
-	writer, err := a.getWritableFile(name)
+	if opts.Mode != model.Append {
+		return a.replace(name, reader, opts)
+	}
+
+	writer, err := a.getWritableFile(name, opts.Mode)
 	if err != nil {
 		return err
 	}
 
-	buf := bufferPool.Get().(*[]byte)
-	defer bufferPool.Put(buf)
+	return errors.Join(a.copyContent(name, writer, reader, opts), writer.Close())
+}
+
+func (a Service) writeAttributes(name string, file *os.File, opts model.WriteOpts, checksum string) error {
+	content := newMetadata(opts)
+	content.Checksum = checksum
+
+	// An append keeps the existing metadata and tags unless new ones are given, the checksum being outdated
+	if opts.Mode == model.Append && content.IsZero() {
+		previous, err := a.readMetadata(name, file)
+		if err != nil {
+			return err
+		}
+
+		if len(previous.Checksum) != 0 {
+			previous.Checksum = ""
+
+			if err = a.writeMetadata(name, file, previous); err != nil {
+				return err
+			}
+		}
+	} else if err := a.writeMetadata(name, file, content); err != nil {
+		return err
+	}
+
+	if a.versioning {
+		if _, err := a.writeVersionID(name, file); err != nil {
+			return err
+		}
+	}
 
-	if _, err = io.CopyBuffer(writer, reader, *buf); err != nil {
-		err = a.ConvertError(err)
+	if opts.Mode != model.Append || len(opts.Tags) != 0 {
+		return a.writeTags(name, file, opts.Tags)
 	}
 
-	return errors.Join(err, writer.Close())
+	return nil
 }
 
 func (a Service) ReadFrom(_ context.Context, name string) (model.ReadAtSeekCloser, error) {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return nil, err
 	}
 
@@ -151,54 +520,98 @@ func (a Service) ReadFrom(_ context.Context, name string) (model.ReadAtSeekClose
 }
 
 func (a Service) UpdateDate(_ context.Context, name string, date time.Time) error {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return err
 	}
 
-	return a.ConvertError(os.Chtimes(a.Path(name), date, date))
+	if err = a.checkSymlinks(name); err != nil {
+		return err
+	}
+
+	return a.ConvertError(a.root.Chtimes(rootName(name), date, date))
 }
 
-func (a Service) Walk(_ context.Context, name string, walkFn func(model.Item) error) error {
-	name = a.Path(name)
+func (a Service) ListSeq(ctx context.Context, name string) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		name, err := a.cleanPath(name)
+		if err != nil {
+			yield(model.Item{}, err)
+			return
+		}
 
-	return a.ConvertError(filepath.Walk(name, func(path string, info fs.FileInfo, err error) error {
+		dir, err := a.open(name, os.O_RDONLY, 0)
 		if err != nil {
-			return err
+			yield(model.Item{}, err)
+			return
 		}
 
-		item := convertToItem(a.getRelativePath(path), info)
-		if a.ignoreFn != nil && a.ignoreFn(item) {
-			if item.IsDir() {
-				return filepath.SkipDir
+		defer func() { _ = dir.Close() }()
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(model.Item{}, err)
+				return
+			}
+
+			files, err := dir.ReadDir(listBatchSize)
+
+			for _, file := range files {
+				item, ok, err := a.readEntry(name, file, false)
+				if err != nil {
+					yield(model.Item{}, err)
+					return
+				}
+
+				if !ok || (a.ignoreFn != nil && a.ignoreFn(item)) {
+					continue
+				}
+
+				if !yield(item, nil) {
+					return
+				}
 			}
-			return nil
-		}
 
-		return walkFn(item)
-	}))
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(model.Item{}, a.ConvertError(err))
+				return
+			}
+		}
+	}
 }
 
 func (a Service) Mkdir(_ context.Context, name string, perm os.FileMode) error {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err = a.checkSymlinks(name); err != nil {
 		return err
 	}
 
-	return a.ConvertError(os.MkdirAll(a.Path(name), perm))
+	return a.ConvertError(a.mkdirAll(name, perm))
 }
 
 func (a Service) Rename(ctx context.Context, oldName, newName string) error {
-	if err := model.ValidPath(oldName); err != nil {
+	oldName, err := a.cleanPath(oldName)
+	if err != nil {
 		return err
 	}
 
-	if err := model.ValidPath(newName); err != nil {
+	newName, err = a.cleanPath(newName)
+	if err != nil {
 		return err
 	}
 
 	newDirPath := path.Dir(strings.TrimSuffix(newName, "/"))
 	if _, err := a.Stat(ctx, newDirPath); err != nil {
 		if model.IsNotExist(err) {
-			if err = a.Mkdir(ctx, newDirPath, model.DirectoryPerm); err != nil {
+			if err = a.Mkdir(ctx, newDirPath, a.dirPerm); err != nil {
 				return a.ConvertError(err)
 			}
 		} else {
@@ -206,15 +619,75 @@ func (a Service) Rename(ctx context.Context, oldName, newName string) error {
 		}
 	}
 
-	return a.ConvertError(os.Rename(a.Path(oldName), a.Path(newName)))
+	// The replaced file is kept in the history of the new name, as an overwrite
+	var archived string
+
+	if a.versioning {
+		if archived, err = a.archiveVersion(newName); err != nil {
+			return err
+		}
+	}
+
+	if err = a.root.Rename(rootName(oldName), rootName(newName)); err != nil {
+		return errors.Join(a.ConvertError(err), a.removeArchived(archived))
+	}
+
+	if err = a.moveSidecar(oldName, newName); err != nil {
+		return err
+	}
+
+	return a.moveVersions(oldName, newName)
 }
 
 func (a Service) RemoveAll(_ context.Context, name string) error {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return err
 	}
 
-	return a.ConvertError(os.RemoveAll(a.Path(name)))
+	if err = a.deleteVersions(name); err != nil {
+		return err
+	}
+
+	if err = a.root.RemoveAll(rootName(name)); err != nil {
+		return a.ConvertError(err)
+	}
+
+	return a.removeSidecar(name)
+}
+
+func (a Service) RemoveMany(ctx context.Context, names []string) ([]model.RemoveResult, error) {
+	results := make([]model.RemoveResult, len(names))
+
+	for index, name := range names {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		results[index].Name = name
+
+		name, err := a.cleanPath(name)
+		if err != nil {
+			results[index].Err = err
+			continue
+		}
+
+		if info, err := a.stat(name); err == nil && !info.IsDir() {
+			if err = a.deleteVersions(name); err != nil {
+				results[index].Err = err
+				continue
+			}
+		}
+
+		if err := a.root.Remove(rootName(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			results[index].Err = a.ConvertError(err)
+			continue
+		}
+
+		results[index].Err = a.removeSidecar(name)
+	}
+
+	return results, nil
 }
 
 func (a Service) ConvertError(err error) error {
@@ -222,9 +695,39 @@ func (a Service) ConvertError(err error) error {
 		return nil
 	}
 
-	if errors.Is(err, fs.ErrNotExist) || strings.HasSuffix(err.Error(), "not a directory") {
-		return model.ErrNotExist(err)
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+
+	switch {
+	case errors.As(err, &pathErr) && err == error(pathErr):
+		return &model.PathError{Op: pathErr.Op, Path: pathname(a.getRelativePath(pathErr.Path)), Backend: Name, Err: convertNativeError(pathErr.Err)}
+	case errors.As(err, &linkErr) && err == error(linkErr):
+		return &model.PathError{Op: linkErr.Op, Path: pathname(a.getRelativePath(linkErr.Old)), Backend: Name, Err: convertNativeError(linkErr.Err)}
+	default:
+		return convertNativeError(err)
 	}
+}
 
-	return err
+func convertNativeError(err error) error {
+	switch {
+	// A non-empty directory is also reported as existing, it has to be checked first
+	case errors.Is(err, syscall.ENOTEMPTY):
+		return model.WrapError(err, model.ErrNotEmpty)
+	case errors.Is(err, syscall.ENOTDIR):
+		return model.WrapError(model.ErrNotExist(err), model.ErrNotDirectory)
+	case errors.Is(err, fs.ErrNotExist):
+		return model.ErrNotExist(err)
+	case errors.Is(err, fs.ErrExist):
+		return model.WrapError(err, model.ErrExist)
+	case errors.Is(err, fs.ErrPermission):
+		return model.WrapError(err, model.ErrPermission)
+	case errors.Is(err, syscall.EISDIR):
+		return model.WrapError(err, model.ErrIsDirectory)
+	case errors.Is(err, syscall.ENOSPC), errors.Is(err, syscall.EDQUOT):
+		return model.WrapError(err, model.ErrQuotaExceeded)
+	case errors.Is(err, syscall.ESTALE), errors.Is(err, syscall.ETIMEDOUT):
+		return model.WrapError(err, model.ErrUnavailable)
+	default:
+		return err
+	}
 }
@@ -0,0 +1,617 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func newTestService(t *testing.T, names ...string) Service {
+	t.Helper()
+
+	instance, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			err = instance.Mkdir(context.Background(), name, model.DirectoryPerm)
+		} else {
+			err = instance.WriteTo(context.Background(), name, strings.NewReader(name), model.WriteOpts{})
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return instance
+}
+
+func TestListSeq(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		limit int
+		want  int
+	}{
+		"full": {
+			0,
+			3,
+		},
+		"break": {
+			1,
+			1,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t, "/first.txt", "/second.txt", "/sub/", "/sub/third.txt")
+
+			var got int
+
+			for _, err := range instance.ListSeq(context.Background(), "/") {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got++
+
+				if got == tc.limit {
+					break
+				}
+			}
+
+			if got != tc.want {
+				t.Errorf("ListSeq() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		limit int
+		want  int
+	}{
+		"full": {
+			0,
+			5,
+		},
+		"break": {
+			2,
+			2,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t, "/first.txt", "/second.txt", "/sub/", "/sub/third.txt")
+
+			var got int
+
+			for _, err := range instance.All(context.Background(), "/", model.WalkOpts{}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got++
+
+				if got == tc.limit {
+					break
+				}
+			}
+
+			if got != tc.want {
+				t.Errorf("All() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		name    string
+		want    []string
+		wantErr error
+	}{
+		"duplicate slashes": {
+			"//sub//",
+			[]string{"/sub", "/sub/third.txt"},
+			nil,
+		},
+		"relative": {
+			"/sub/../..",
+			nil,
+			model.ErrRelativePath,
+		},
+		"nul byte": {
+			"/sub\x00",
+			nil,
+			model.ErrInvalidPath,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t, "/first.txt", "/sub/", "/sub/third.txt")
+
+			var got []string
+
+			gotErr := instance.Walk(context.Background(), tc.name, func(item model.Item) error {
+				got = append(got, item.Pathname)
+
+				return nil
+			})
+
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("Walk() error = `%v`, want `%v`", gotErr, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Walk() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWalkOpts(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		opts    model.WalkOpts
+		cancel  bool
+		want    []string
+		wantErr bool
+	}{
+		"default": {
+			model.WalkOpts{},
+			false,
+			[]string{"/", "/link", "/sub", "/sub/deep", "/sub/deep/file.txt", "/sub/loop"},
+			false,
+		},
+		"max depth": {
+			model.WalkOpts{MaxDepth: 1},
+			false,
+			[]string{"/", "/link", "/sub"},
+			false,
+		},
+		"follow symlinks": {
+			model.WalkOpts{FollowSymlinks: true},
+			false,
+			[]string{"/", "/link", "/link/file.txt", "/sub", "/sub/deep", "/sub/deep/file.txt", "/sub/loop"},
+			false,
+		},
+		"cancelled": {
+			model.WalkOpts{},
+			true,
+			nil,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t, "/sub/deep/", "/sub/deep/file.txt")
+
+			for name, target := range map[string]string{"link": "sub/deep", "sub/loop": ".."} {
+				if err := os.Symlink(target, instance.Path(name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+
+			var got []string
+
+			for item, err := range instance.All(ctx, "/", tc.opts) {
+				if err != nil {
+					if !tc.wantErr {
+						t.Error(err)
+					}
+
+					continue
+				}
+
+				got = append(got, item.Pathname)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("All() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWalkContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	instance, err := New(t.TempDir(), WithSidecarMetadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	for _, name := range []string{"/broken.txt", "/valid.txt"} {
+		if err = instance.WriteTo(ctx, name, strings.NewReader(name), model.WriteOpts{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = instance.root.WriteFile(sidecarPath("/broken.txt"), []byte("{"), model.RegularFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, continueOnError := range []bool{false, true} {
+		var got []string
+
+		err = instance.walk(ctx, "/", model.WalkOpts{ContinueOnError: continueOnError, WithMetadata: true}, func(item model.Item) error {
+			got = append(got, item.Pathname)
+			return nil
+		})
+
+		if err == nil {
+			t.Errorf("walk(%t) = nil, want an error", continueOnError)
+		}
+
+		if want := map[bool]int{false: 1, true: 2}[continueOnError]; len(got) != want {
+			t.Errorf("walk(%t) = %v, want %d items", continueOnError, got, want)
+		}
+	}
+}
+
+func TestListPage(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/a.txt", "/b.txt", "/c/", "/c/f.txt", "/d.txt", "/e.txt", "/g.txt").WithIgnoreFn(func(item model.Item) bool {
+		return item.Pathname == "/d.txt" || item.Pathname == "/e.txt"
+	})
+
+	var got []string
+	var opts model.ListOpts
+	var pages int
+
+	for {
+		opts.Limit = 2
+
+		page, err := instance.ListPage(context.Background(), "/", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pages++
+
+		for _, item := range page.Items {
+			got = append(got, item.Pathname)
+		}
+
+		if len(page.NextToken) == 0 {
+			break
+		}
+
+		opts.ContinuationToken = page.NextToken
+	}
+
+	if want := "/a.txt,/b.txt,/c,/g.txt"; strings.Join(got, ",") != want || pages != 2 {
+		t.Errorf("ListPage() = `%s` in %d pages, want `%s` in 2 pages", strings.Join(got, ","), pages, want)
+	}
+
+	page, err := instance.ListPage(context.Background(), "/", model.ListOpts{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = instance.ListPage(context.Background(), "/c", model.ListOpts{ContinuationToken: page.NextToken}); !errors.Is(err, model.ErrInvalidToken) {
+		t.Errorf("ListPage() = `%v`, want an invalid token for another directory", err)
+	}
+}
+
+func TestListPageTokenSecret(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(directory, name), nil, model.RegularFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newInstance := func(options ...ConfigOption) Service {
+		instance, err := New(directory, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { _ = instance.Close() })
+
+		return instance
+	}
+
+	ctx := context.Background()
+
+	page, err := newInstance(WithTokenSecret("secret")).ListPage(ctx, "/", model.ListOpts{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := newInstance(WithTokenSecret("secret")).ListPage(ctx, "/", model.ListOpts{ContinuationToken: page.NextToken}); err != nil || len(got.Items) != 1 || got.Items[0].Pathname != "/b.txt" {
+		t.Errorf("ListPage() = (%+v, `%v`), want the next page with the same secret", got, err)
+	}
+
+	if _, err = newInstance().ListPage(ctx, "/", model.ListOpts{ContinuationToken: page.NextToken}); !errors.Is(err, model.ErrInvalidToken) {
+		t.Errorf("ListPage() = `%v`, want an invalid token for another secret", err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		pattern string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"simple": {
+			args{
+				pattern: "/*.txt",
+			},
+			"/a.txt,/b.txt",
+		},
+		"globstar": {
+			args{
+				pattern: "/**/*.png",
+			},
+			"/photos/2024/summer.png,/photos/winter.png",
+		},
+		"pruned": {
+			args{
+				pattern: "/photos/*/*.png",
+			},
+			"/photos/2024/summer.png",
+		},
+		"missing directory": {
+			args{
+				pattern: "/videos/*",
+			},
+			"",
+		},
+	}
+
+	instance := newTestService(t, "/a.txt", "/b.txt", "/photos/", "/photos/winter.png", "/photos/2024/", "/photos/2024/summer.png")
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			items, err := instance.Glob(context.Background(), tc.args.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Pathname)
+			}
+
+			if strings.Join(got, ",") != tc.want {
+				t.Errorf("Glob() = `%s`, want `%s`", strings.Join(got, ","), tc.want)
+			}
+		})
+	}
+}
+
+func TestRemoveMany(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/a.txt", "/b.txt", "/dir/", "/dir/c.txt")
+
+	results, err := instance.RemoveMany(context.Background(), []string{"/a.txt", "/missing.txt", "/../b.txt", "/dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantErrs := []bool{false, false, true, true}
+
+	for index, result := range results {
+		if (result.Err != nil) != wantErrs[index] {
+			t.Errorf("RemoveMany(`%s`) = `%v`, want error %t", result.Name, result.Err, wantErrs[index])
+		}
+	}
+
+	if _, err := instance.Stat(context.Background(), "/a.txt"); !model.IsNotExist(err) {
+		t.Errorf("Stat() = `%v`, want not exist", err)
+	}
+}
+
+func TestWriteToMode(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		mode model.WriteMode
+	}
+
+	cases := map[string]struct {
+		args    args
+		want    string
+		wantErr error
+	}{
+		"overwrite": {
+			args{
+				mode: model.Overwrite,
+			},
+			"second",
+			nil,
+		},
+		"exclusive": {
+			args{
+				mode: model.CreateExclusive,
+			},
+			"first",
+			model.ErrExist,
+		},
+		"append": {
+			args{
+				mode: model.Append,
+			},
+			"firstsecond",
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newTestService(t)
+			ctx := context.Background()
+
+			if err := instance.WriteTo(ctx, "/file.txt", strings.NewReader("first"), model.WriteOpts{Mode: tc.args.mode}); err != nil {
+				t.Fatal(err)
+			}
+
+			gotErr := instance.WriteTo(ctx, "/file.txt", strings.NewReader("second"), model.WriteOpts{Mode: tc.args.mode})
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("WriteTo() = `%v`, want `%v`", gotErr, tc.wantErr)
+			}
+
+			reader, err := instance.ReadFrom(ctx, "/file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := io.ReadAll(reader)
+			_ = reader.Close()
+
+			if err != nil || string(got) != tc.want {
+				t.Errorf("ReadFrom() = (`%s`, `%v`), want `%s`", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/a.txt", "/dir/", "/dir/b.md", "/dir/sub/", "/dir/sub/c.txt")
+
+	got, err := instance.Usage(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Bytes != 29 || got.Files != 3 || got.Directories != 2 {
+		t.Errorf("Usage() = (%d bytes, %d files, %d directories), want (29, 3, 2)", got.Bytes, got.Files, got.Directories)
+	}
+
+	wantChildren := map[string]model.UsageEntry{
+		"a.txt": {Bytes: 6, Files: 1},
+		"dir":   {Bytes: 23, Files: 2},
+	}
+
+	if !reflect.DeepEqual(got.ByChild, wantChildren) {
+		t.Errorf("Usage().ByChild = %+v, want %+v", got.ByChild, wantChildren)
+	}
+
+	wantExtensions := map[string]model.UsageEntry{
+		".txt": {Bytes: 20, Files: 2},
+		".md":  {Bytes: 9, Files: 1},
+	}
+
+	if !reflect.DeepEqual(got.ByExtension, wantExtensions) {
+		t.Errorf("Usage().ByExtension = %+v, want %+v", got.ByExtension, wantExtensions)
+	}
+
+	if got.FreeBytes <= 0 {
+		t.Errorf("Usage().FreeBytes = %d, want positive", got.FreeBytes)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options []ConfigOption
+		want    model.Capabilities
+	}{
+		"default": {
+			nil,
+			model.Capabilities{
+				AtomicRename:   true,
+				PreservesMtime: true,
+				RangeReads:     true,
+				ServerSideCopy: true,
+				NativeWatch:    nativeWatch,
+			},
+		},
+		"versioning": {
+			[]ConfigOption{WithVersioning()},
+			model.Capabilities{
+				AtomicRename:   true,
+				PreservesMtime: true,
+				RangeReads:     true,
+				ServerSideCopy: true,
+				Versioning:     true,
+				NativeWatch:    nativeWatch,
+			},
+		},
+		"signature": {
+			[]ConfigOption{WithSignature("https://example.com/files", "secret")},
+			model.Capabilities{
+				AtomicRename:   true,
+				PreservesMtime: true,
+				RangeReads:     true,
+				ServerSideCopy: true,
+				NativeWatch:    nativeWatch,
+				SignedURL:      true,
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := New(t.TempDir(), tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { _ = instance.Close() })
+
+			if got := instance.Capabilities(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Capabilities() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const metadataAttribute = "user.absto"
+
+var errNoAttribute = errors.New("no attribute")
+
+type metadata struct {
+	ModTime         time.Time         `json:"modTime,omitzero"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	ContentType     string            `json:"contentType,omitempty"`
+	ContentEncoding string            `json:"contentEncoding,omitempty"`
+	CacheControl    string            `json:"cacheControl,omitempty"`
+	Checksum        string            `json:"checksum,omitempty"`
+}
+
+func newMetadata(opts model.WriteOpts) metadata {
+	return metadata{
+		ModTime:         opts.ModTime,
+		Metadata:        opts.Metadata,
+		ContentType:     opts.ContentType,
+		ContentEncoding: opts.ContentEncoding,
+		CacheControl:    opts.CacheControl,
+	}
+}
+
+func (m metadata) IsZero() bool {
+	return m.ModTime.IsZero() && len(m.Metadata) == 0 && len(m.ContentType) == 0 && len(m.ContentEncoding) == 0 && len(m.CacheControl) == 0 && len(m.Checksum) == 0
+}
+
+func (m metadata) apply(item model.Item) model.Item {
+	item.OriginalDate = m.ModTime
+	item.Metadata = m.Metadata
+	item.ContentType = m.ContentType
+	item.ContentEncoding = m.ContentEncoding
+	item.CacheControl = m.CacheControl
+	item.Checksum = m.Checksum
+
+	return item
+}
+
+func (a Service) readMetadata(name string, file *os.File) (metadata, error) {
+	var output metadata
+
+	content, err := a.getAttribute(name, file, metadataAttribute)
+	if err != nil {
+		if errors.Is(err, errNoAttribute) || errors.Is(err, errors.ErrUnsupported) {
+			return output, nil
+		}
+
+		return output, fmt.Errorf("get metadata: %w", err)
+	}
+
+	if err = json.Unmarshal(content, &output); err != nil {
+		return output, fmt.Errorf("unmarshal metadata: %w", err)
+	}
+
+	return output, nil
+}
+
+func (a Service) writeMetadata(name string, file *os.File, content metadata) error {
+	if content.IsZero() {
+		if err := a.removeAttribute(name, file, metadataAttribute); err != nil && !errors.Is(err, errNoAttribute) && !errors.Is(err, errors.ErrUnsupported) {
+			return fmt.Errorf("remove metadata: %w", err)
+		}
+
+		return nil
+	}
+
+	payload, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+
+	if err = a.setAttribute(name, file, metadataAttribute, payload); err != nil {
+		return fmt.Errorf("set metadata: %w", err)
+	}
+
+	return nil
+}
+
+// withMetadata reads the metadata of a regular file, an unreadable one being returned as is.
+func (a Service) withMetadata(name string, item model.Item) (model.Item, error) {
+	if !item.FileMode.IsRegular() {
+		return item, nil
+	}
+
+	var file *os.File
+
+	if !a.sidecar {
+		var err error
+
+		file, err = a.root.Open(rootName(name))
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
+				return item, nil
+			}
+
+			return item, a.ConvertError(err)
+		}
+
+		defer func() { _ = file.Close() }()
+	}
+
+	content, err := a.readMetadata(name, file)
+	if err != nil {
+		return item, err
+	}
+
+	return content.apply(item), nil
+}
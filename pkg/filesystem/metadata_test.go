@@ -0,0 +1,207 @@
+package filesystem
+
+import (
+	"context"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const contentChecksum = "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73"
+
+// metadataStores are the options of the two stores of the metadata.
+var metadataStores = map[string][]ConfigOption{
+	"xattr":   nil,
+	"sidecar": {WithSidecarMetadata()},
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		opts model.WriteOpts
+	}
+
+	cases := map[string]struct {
+		args args
+		want metadata
+	}{
+		"empty": {
+			args{
+				opts: model.WriteOpts{},
+			},
+			metadata{
+				Checksum: contentChecksum,
+			},
+		},
+		"full": {
+			args{
+				opts: model.WriteOpts{
+					ModTime:         modTime,
+					ContentType:     "text/plain",
+					ContentEncoding: "gzip",
+					CacheControl:    "no-cache",
+					Metadata: map[string]string{
+						"author": "absto",
+					},
+				},
+			},
+			metadata{
+				ModTime:         modTime,
+				ContentType:     "text/plain",
+				ContentEncoding: "gzip",
+				CacheControl:    "no-cache",
+				Metadata: map[string]string{
+					"author": "absto",
+				},
+				Checksum: contentChecksum,
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		for store, options := range metadataStores {
+			t.Run(intention+" "+store, func(t *testing.T) {
+				t.Parallel()
+
+				instance, err := New(t.TempDir(), options...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ctx := context.Background()
+
+				if err := instance.WriteTo(ctx, "/file.txt", strings.NewReader("content"), tc.args.opts); err != nil {
+					t.Fatal(err)
+				}
+
+				item, err := instance.Stat(ctx, "/file.txt")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := metadata{
+					ModTime:         item.OriginalDate,
+					Metadata:        item.Metadata,
+					ContentType:     item.ContentType,
+					ContentEncoding: item.ContentEncoding,
+					CacheControl:    item.CacheControl,
+					Checksum:        item.Checksum,
+				}
+
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("Metadata() = %+v, want %+v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestMetadataLifecycle(t *testing.T) {
+	t.Parallel()
+
+	for store, options := range metadataStores {
+		t.Run(store, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := New(t.TempDir(), options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			if err = instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("content"), model.WriteOpts{ContentType: "text/plain", Tags: map[string]string{"retention": "30d"}}); !model.IsNotExist(err) {
+				t.Errorf("WriteTo() = `%v`, want not exist", err)
+			}
+
+			if err = instance.Mkdir(ctx, "/dir", model.DirectoryPerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("content"), model.WriteOpts{ContentType: "text/plain", Tags: map[string]string{"retention": "30d"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader(" appended"), model.WriteOpts{Mode: model.Append}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.Rename(ctx, "/dir", "/moved"); err != nil {
+				t.Fatal(err)
+			}
+
+			item, err := instance.Stat(ctx, "/moved/file.txt")
+			if err != nil || item.ContentType != "text/plain" || len(item.Checksum) != 0 {
+				t.Errorf("Stat() = (%+v, `%v`), want content type kept and checksum dropped", item, err)
+			}
+
+			if tags, err := instance.GetTags(ctx, "/moved/file.txt"); err != nil || tags["retention"] != "30d" {
+				t.Errorf("GetTags() = (%v, `%v`), want tags kept", tags, err)
+			}
+
+			if err = instance.RemoveAll(ctx, "/moved"); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = fs.Stat(instance.root.FS(), sidecarPath("/moved")); !model.IsNotExist(instance.ConvertError(err)) {
+				t.Errorf("sidecar = `%v`, want removed", err)
+			}
+
+			if err = instance.Mkdir(ctx, "/moved", model.DirectoryPerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.WriteTo(ctx, "/moved/file.txt", strings.NewReader("content"), model.WriteOpts{Mode: model.Append}); err != nil {
+				t.Fatal(err)
+			}
+
+			if item, err = instance.Stat(ctx, "/moved/file.txt"); err != nil || len(item.ContentType) != 0 {
+				t.Errorf("Stat() = (%+v, `%v`), want no stale metadata", item, err)
+			}
+		})
+	}
+}
+
+func TestListMetadata(t *testing.T) {
+	t.Parallel()
+
+	for store, opts := range metadataStores {
+		t.Run(store, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := New(t.TempDir(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			if err = instance.WriteTo(ctx, "/file.txt", strings.NewReader("content"), model.WriteOpts{ContentType: "text/plain"}); err != nil {
+				t.Fatal(err)
+			}
+
+			items, err := instance.List(ctx, "/")
+			if err != nil || len(items) != 1 || len(items[0].ContentType) != 0 {
+				t.Errorf("List() = (%+v, `%v`), want the file without its metadata", items, err)
+			}
+
+			page, err := instance.ListPage(ctx, "/", model.ListOpts{WithMetadata: true})
+			if err != nil || len(page.Items) != 1 || page.Items[0].ContentType != "text/plain" {
+				t.Errorf("ListPage() = (%+v, `%v`), want the file with its metadata", page, err)
+			}
+
+			for item, err := range instance.All(ctx, "/file.txt", model.WalkOpts{WithMetadata: true}) {
+				if err != nil || item.ContentType != "text/plain" {
+					t.Errorf("All() = (%+v, `%v`), want the file with its metadata", item, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// noGroup keeps the group of the process for the created files and directories.
+const noGroup = -1
+
+// ParseMode parses an octal mode, such as `0640`.
+func ParseMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimSpace(value), 8, 32)
+	if err != nil || mode > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("invalid mode `%s`", value)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// ParseGroup returns the ID of the group given by name or ID, an empty value keeping the group of the process.
+func ParseGroup(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return noGroup, nil
+	}
+
+	if gid, err := strconv.Atoi(value); err == nil && gid >= 0 {
+		return gid, nil
+	}
+
+	group, err := user.LookupGroup(value)
+	if err != nil {
+		return noGroup, fmt.Errorf("lookup group: %w", err)
+	}
+
+	gid, err := strconv.Atoi(group.Gid)
+	if err != nil {
+		return noGroup, fmt.Errorf("group `%s` has no numeric id: %w", value, errors.ErrUnsupported)
+	}
+
+	return gid, nil
+}
+
+// setFileOwnership applies the configured group and the given mode to a written file, regardless of the umask.
+func (a Service) setFileOwnership(file *os.File, perm os.FileMode) error {
+	if a.gid != noGroup {
+		if err := file.Chown(-1, a.gid); err != nil {
+			return a.ConvertError(err)
+		}
+	}
+
+	return a.ConvertError(file.Chmod(perm))
+}
+
+// mkdirAll creates the missing directories one by one, each one having the given mode and the configured group.
+func (a Service) mkdirAll(name string, perm os.FileMode) error {
+	// The setgid bit is refused at creation, it is set afterward
+	mode := perm
+	if a.setgid {
+		mode |= os.ModeSetgid
+	}
+
+	var current string
+
+	for segment := range strings.SplitSeq(rootName(name), "/") {
+		if segment == "." {
+			continue
+		}
+
+		current = path.Join(current, segment)
+
+		err := a.root.Mkdir(current, perm)
+		if errors.Is(err, fs.ErrExist) {
+			info, err := a.root.Stat(current)
+			if err != nil {
+				return err
+			}
+
+			if !info.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: current, Err: syscall.ENOTDIR}
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// A change of group may clear the setgid bit, the mode is set last
+		if a.gid != noGroup {
+			if err = a.root.Lchown(current, -1, a.gid); err != nil {
+				return err
+			}
+		}
+
+		if err = a.root.Chmod(current, mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package filesystem
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		value   string
+		want    os.FileMode
+		wantErr bool
+	}{
+		"octal": {
+			"0640",
+			0o640,
+			false,
+		},
+		"short": {
+			"750",
+			0o750,
+			false,
+		},
+		"not octal": {
+			"0690",
+			0,
+			true,
+		},
+		"too large": {
+			"17777",
+			0,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := ParseMode(tc.value)
+
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("ParseMode() error = `%v`, want error %t", gotErr, tc.wantErr)
+			}
+
+			if got != tc.want {
+				t.Errorf("ParseMode() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	t.Parallel()
+
+	instance, err := New(t.TempDir(), WithPermissions(0o640, 0o750), WithGroup(os.Getgid()), WithSetgid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = instance.Close() })
+
+	ctx := context.Background()
+
+	if err = instance.Mkdir(ctx, "/explicit/nested", 0o705); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.WriteTo(ctx, "/file.txt", strings.NewReader("content"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.Rename(ctx, "/file.txt", "/implicit/nested/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.WriteTo(ctx, "/appended.txt", strings.NewReader("created"), model.WriteOpts{Mode: model.Append}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.WriteTo(ctx, "/existing.txt", strings.NewReader("content"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.root.Chmod("existing.txt", 0o604); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.WriteTo(ctx, "/existing.txt", strings.NewReader(" appended"), model.WriteOpts{Mode: model.Append}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]fs.FileMode{
+		"appended.txt":             0o640,
+		"existing.txt":             0o604,
+		"explicit":                 fs.ModeDir | os.ModeSetgid | 0o705,
+		"explicit/nested":          fs.ModeDir | os.ModeSetgid | 0o705,
+		"implicit":                 fs.ModeDir | os.ModeSetgid | 0o750,
+		"implicit/nested":          fs.ModeDir | os.ModeSetgid | 0o750,
+		"implicit/nested/file.txt": 0o640,
+	}
+
+	for name, want := range cases {
+		info, err := instance.root.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Mode() != want {
+			t.Errorf("`%s` mode = %s, want %s", name, info.Mode(), want)
+		}
+	}
+}
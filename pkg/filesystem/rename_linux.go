@@ -0,0 +1,31 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path"
+
+	"golang.org/x/sys/unix"
+)
+
+func renameNoReplace(root *os.Root, directory, oldName, newName string) error {
+	dir, err := root.Open(directory)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = dir.Close() }()
+
+	fd := int(dir.Fd())
+
+	err = unix.Renameat2(fd, oldName, fd, newName, unix.RENAME_NOREPLACE)
+	if errors.Is(err, unix.EINVAL) || errors.Is(err, unix.ENOSYS) {
+		return errors.ErrUnsupported
+	}
+
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: path.Join(directory, oldName), New: path.Join(directory, newName), Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+)
+
+func renameNoReplace(_ *os.Root, _, _, _ string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,222 @@
+package filesystem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// SymlinkPolicy defines how the symlinks found under the root directory are handled. A symlink never gives access
+// outside the root directory, whatever the policy.
+type SymlinkPolicy uint8
+
+const (
+	// SymlinkFollow resolves the symlinks pointing inside the root directory, the other ones being listed as links.
+	SymlinkFollow SymlinkPolicy = iota
+	// SymlinkRefuse rejects the names going through a symlink and hides them from the listings.
+	SymlinkRefuse
+	// SymlinkExpose lists the symlinks as links without resolving them, the content being read through them.
+	SymlinkExpose
+)
+
+var ErrSymlink = fmt.Errorf("symlink refused: %w", model.ErrPermission)
+
+func ParseSymlinkPolicy(value string) (SymlinkPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "follow":
+		return SymlinkFollow, nil
+	case "refuse":
+		return SymlinkRefuse, nil
+	case "expose":
+		return SymlinkExpose, nil
+	default:
+		return SymlinkFollow, fmt.Errorf("unknown symlink policy `%s`", value)
+	}
+}
+
+// maxSymlinks is the number of symlinks resolved in a name before leaving the loop to os.Root.
+const maxSymlinks = 40
+
+var errEscape = fmt.Errorf("symlink outside the root directory: %w", model.ErrPermission)
+
+// rootName returns the name relative to the root directory, as expected by os.Root.
+func rootName(name string) string {
+	if name = strings.Trim(name, "/"); len(name) == 0 {
+		return "."
+	}
+
+	return name
+}
+
+// pathname returns the name exposed in the items, relative to the root directory with a leading slash.
+func pathname(name string) string {
+	if name == "." {
+		return "/"
+	}
+
+	return "/" + strings.TrimPrefix(name, "/")
+}
+
+func isSymlink(info fs.FileInfo) bool {
+	return info.Mode()&fs.ModeSymlink != 0
+}
+
+// checkDirectories rejects a name whose parent directories go through a refused symlink or one leading outside the
+// root directory, os.Root failing on them with an unexported error.
+func (a Service) checkDirectories(name string) error {
+	return a.checkSymlinks(path.Dir(name))
+}
+
+// checkSymlinks rejects a name going through a symlink when they are refused, or through one leading outside the root
+// directory otherwise.
+func (a Service) checkSymlinks(name string) error {
+	var current string
+
+	pending := strings.Split(strings.Trim(name, "/"), "/")
+
+	for hops := 0; len(pending) > 0; {
+		segment := pending[0]
+		pending = pending[1:]
+
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if len(current) == 0 {
+				return &model.PathError{Op: "resolve", Path: pathname(name), Backend: Name, Err: errEscape}
+			}
+
+			if current = path.Dir(current); current == "." {
+				current = ""
+			}
+
+			continue
+		}
+
+		next := path.Join(current, segment)
+
+		info, err := a.root.Lstat(next)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return a.ConvertError(err)
+		}
+
+		if !isSymlink(info) {
+			current = next
+			continue
+		}
+
+		if a.symlinkPolicy == SymlinkRefuse {
+			return &model.PathError{Op: "resolve", Path: pathname(next), Backend: Name, Err: ErrSymlink}
+		}
+
+		if hops++; hops > maxSymlinks {
+			return nil
+		}
+
+		target, err := a.root.Readlink(next)
+		if err != nil {
+			return a.ConvertError(err)
+		}
+
+		if filepath.IsAbs(target) || len(filepath.VolumeName(target)) != 0 {
+			return &model.PathError{Op: "resolve", Path: pathname(next), Backend: Name, Err: errEscape}
+		}
+
+		pending = append(strings.Split(filepath.ToSlash(target), "/"), pending...)
+	}
+
+	return nil
+}
+
+func (a Service) stat(name string) (fs.FileInfo, error) {
+	if err := a.checkSymlinks(name); err != nil {
+		return nil, err
+	}
+
+	if a.symlinkPolicy == SymlinkFollow {
+		return a.root.Stat(rootName(name))
+	}
+
+	return a.root.Lstat(rootName(name))
+}
+
+// resolve returns the information of the target of a symlink according to the policy, false being returned for a hidden one.
+func (a Service) resolve(name string, info fs.FileInfo) (fs.FileInfo, bool) {
+	if !isSymlink(info) {
+		return info, true
+	}
+
+	switch a.symlinkPolicy {
+	case SymlinkRefuse:
+		return nil, false
+	case SymlinkFollow:
+		if target, err := a.root.Stat(rootName(name)); err == nil {
+			return target, true
+		}
+	}
+
+	return info, true
+}
+
+// item converts the information read with a lstat, false being returned for a hidden symlink. The metadata are read
+// when asked only, opening each file being costly for the listings.
+func (a Service) item(name string, info fs.FileInfo, withMetadata bool) (model.Item, bool, error) {
+	info, ok := a.resolve(name, info)
+	if !ok {
+		return model.Item{}, false, nil
+	}
+
+	item := convertToItem(pathname(name), info)
+	if !withMetadata {
+		return item, true, nil
+	}
+
+	item, err := a.withMetadata(name, item)
+
+	return item, true, err
+}
+
+func (a Service) open(name string, flag int, perm os.FileMode) (*os.File, error) {
+	if err := a.checkSymlinks(name); err != nil {
+		return nil, err
+	}
+
+	file, err := a.root.OpenFile(rootName(name), flag, perm)
+
+	return file, a.ConvertError(err)
+}
+
+// createTemp creates a new file in the given directory, with a random suffix after the prefix.
+func (a Service) createTemp(directory, prefix string, perm os.FileMode) (*os.File, string, error) {
+	for {
+		suffix := make([]byte, 8)
+		_, _ = rand.Read(suffix)
+
+		name := path.Join(directory, prefix+hex.EncodeToString(suffix))
+
+		file, err := a.root.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if err == nil {
+			if err = a.setFileOwnership(file, perm); err != nil {
+				return nil, "", errors.Join(err, file.Close(), a.root.Remove(name))
+			}
+
+			return file, name, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, "", a.ConvertError(err)
+		}
+	}
+}
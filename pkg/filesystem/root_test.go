@@ -0,0 +1,251 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// newEscapeService creates a service with symlinks pointing outside of its root directory, next to a secret file.
+func newEscapeService(t *testing.T, symlinkPolicy SymlinkPolicy) (Service, string) {
+	t.Helper()
+
+	directory := t.TempDir()
+	outside := filepath.Join(directory, "outside")
+	root := filepath.Join(directory, "root")
+
+	for _, name := range []string{outside, root, filepath.Join(root, "inside")} {
+		if err := os.Mkdir(name, model.DirectoryPerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, content := range map[string]string{
+		filepath.Join(outside, "secret.txt"):      "secret",
+		filepath.Join(root, "inside", "file.txt"): "inside",
+	} {
+		if err := os.WriteFile(name, []byte(content), model.RegularFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, target := range map[string]string{
+		"absolute":  outside,
+		"relative":  "../outside",
+		"secret":    filepath.Join(outside, "secret.txt"),
+		"chain":     "absolute/secret.txt",
+		"link.txt":  "inside/file.txt",
+		"linkdir":   "inside",
+		"dangling":  "missing.txt",
+		"loop":      "loop",
+		"traversal": "inside/../../outside",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	instance, err := New(root, WithSymlinkPolicy(symlinkPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = instance.Close() })
+
+	return instance, outside
+}
+
+func TestEscape(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]func(context.Context, Service) error{
+		"stat absolute": func(ctx context.Context, instance Service) error {
+			_, err := instance.Stat(ctx, "/absolute/secret.txt")
+			return err
+		},
+		"read absolute": func(ctx context.Context, instance Service) error {
+			_, err := instance.ReadFrom(ctx, "/absolute/secret.txt")
+			return err
+		},
+		"read relative": func(ctx context.Context, instance Service) error {
+			_, err := instance.ReadFrom(ctx, "/relative/secret.txt")
+			return err
+		},
+		"read file": func(ctx context.Context, instance Service) error {
+			_, err := instance.ReadFrom(ctx, "/secret")
+			return err
+		},
+		"read chain": func(ctx context.Context, instance Service) error {
+			_, err := instance.ReadFrom(ctx, "/chain")
+			return err
+		},
+		"read traversal": func(ctx context.Context, instance Service) error {
+			_, err := instance.ReadFrom(ctx, "/traversal/secret.txt")
+			return err
+		},
+		"write": func(ctx context.Context, instance Service) error {
+			return instance.WriteTo(ctx, "/absolute/secret.txt", strings.NewReader("overwritten"), model.WriteOpts{})
+		},
+		"create": func(ctx context.Context, instance Service) error {
+			return instance.WriteTo(ctx, "/relative/created.txt", strings.NewReader("created"), model.WriteOpts{})
+		},
+		"append through file": func(ctx context.Context, instance Service) error {
+			return instance.WriteTo(ctx, "/secret", strings.NewReader("appended"), model.WriteOpts{Mode: model.Append})
+		},
+		"list": func(ctx context.Context, instance Service) error {
+			_, err := instance.List(ctx, "/absolute")
+			return err
+		},
+		"walk": func(ctx context.Context, instance Service) error {
+			return instance.Walk(ctx, "/relative", func(model.Item) error { return nil })
+		},
+		"mkdir": func(ctx context.Context, instance Service) error {
+			return instance.Mkdir(ctx, "/absolute/created", model.DirectoryPerm)
+		},
+		"rename from": func(ctx context.Context, instance Service) error {
+			return instance.Rename(ctx, "/absolute/secret.txt", "/stolen.txt")
+		},
+		"rename to": func(ctx context.Context, instance Service) error {
+			return instance.Rename(ctx, "/inside/file.txt", "/absolute/planted.txt")
+		},
+		"remove": func(ctx context.Context, instance Service) error {
+			return instance.RemoveAll(ctx, "/absolute/secret.txt")
+		},
+		"update date": func(ctx context.Context, instance Service) error {
+			return instance.UpdateDate(ctx, "/secret", model.Item{}.Date)
+		},
+		"tags": func(ctx context.Context, instance Service) error {
+			return instance.SetTags(ctx, "/secret", map[string]string{"stolen": "true"})
+		},
+		"usage": func(ctx context.Context, instance Service) error {
+			_, err := instance.Usage(ctx, "/absolute")
+			return err
+		},
+	}
+
+	for intention, action := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			for _, symlinkPolicy := range []SymlinkPolicy{SymlinkFollow, SymlinkRefuse, SymlinkExpose} {
+				instance, outside := newEscapeService(t, symlinkPolicy)
+
+				if err := action(context.Background(), instance); !model.IsPermission(err) {
+					t.Errorf("policy %d: action() = `%v`, want a permission error", symlinkPolicy, err)
+				}
+
+				content, err := os.ReadFile(filepath.Join(outside, "secret.txt"))
+				if err != nil || string(content) != "secret" {
+					t.Errorf("policy %d: secret = (`%s`, `%v`), want untouched", symlinkPolicy, content, err)
+				}
+
+				entries, err := os.ReadDir(outside)
+				if err != nil || len(entries) != 1 {
+					t.Errorf("policy %d: outside has %d entries, want 1", symlinkPolicy, len(entries))
+				}
+			}
+		})
+	}
+}
+
+func TestSymlinkPolicy(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		linkMode fs.FileMode
+		linkErr  error
+		listed   []string
+		walked   int
+		readErr  error
+	}
+
+	cases := map[string]struct {
+		symlinkPolicy SymlinkPolicy
+		want          want
+	}{
+		"follow": {
+			SymlinkFollow,
+			want{
+				linkMode: model.RegularFilePerm,
+				listed:   []string{"absolute", "chain", "dangling", "inside", "link.txt", "linkdir", "loop", "relative", "secret", "traversal"},
+				walked:   12,
+			},
+		},
+		"refuse": {
+			SymlinkRefuse,
+			want{
+				linkErr: ErrSymlink,
+				listed:  []string{"inside"},
+				walked:  3,
+				readErr: ErrSymlink,
+			},
+		},
+		"expose": {
+			SymlinkExpose,
+			want{
+				linkMode: fs.ModeSymlink | 0o777,
+				listed:   []string{"absolute", "chain", "dangling", "inside", "link.txt", "linkdir", "loop", "relative", "secret", "traversal"},
+				walked:   12,
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, _ := newEscapeService(t, tc.symlinkPolicy)
+			ctx := context.Background()
+
+			item, err := instance.Stat(ctx, "/link.txt")
+			if !errors.Is(err, tc.want.linkErr) {
+				t.Errorf("Stat() error = `%v`, want `%v`", err, tc.want.linkErr)
+			}
+
+			if err == nil && item.FileMode.Type() != tc.want.linkMode.Type() {
+				t.Errorf("Stat() mode = %s, want %s", item.FileMode, tc.want.linkMode)
+			}
+
+			items, err := instance.List(ctx, "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var listed []string
+			for _, item := range items {
+				listed = append(listed, item.Name())
+			}
+
+			if strings.Join(listed, ",") != strings.Join(tc.want.listed, ",") {
+				t.Errorf("List() = %v, want %v", listed, tc.want.listed)
+			}
+
+			var walked int
+			if err = instance.Walk(ctx, "/", func(model.Item) error {
+				walked++
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if walked != tc.want.walked {
+				t.Errorf("Walk() = %d items, want %d", walked, tc.want.walked)
+			}
+
+			reader, err := instance.ReadFrom(ctx, "/linkdir/file.txt")
+			if err == nil {
+				_ = reader.Close()
+			}
+
+			if !errors.Is(err, tc.want.readErr) {
+				t.Errorf("ReadFrom() error = `%v`, want `%v`", err, tc.want.readErr)
+			}
+		})
+	}
+}
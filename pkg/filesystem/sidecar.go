@@ -0,0 +1,203 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// metadataDirectory holds the attributes of the files when the filesystem doesn't support the extended attributes,
+// with the same layout as the root directory. The sidecar of a file is a JSON object of its attributes.
+const (
+	metadataDirectory = internalDirectory + "/metadata"
+	probeAttribute    = "user.absto.probe"
+	tempSidecarPrefix = tempPrefix + "sidecar-"
+	tempProbePrefix   = tempPrefix + "probe-"
+)
+
+// sidecarPath returns the name of the sidecar of the given name relative to the root directory.
+func sidecarPath(name string) string {
+	return rootName(path.Join(metadataDirectory, name))
+}
+
+// supportsXattr checks that the extended attributes can be set on a file of the root directory, a read-only one
+// being assumed to support them.
+func (a Service) supportsXattr() bool {
+	file, name, err := a.createTemp(".", tempProbePrefix, a.filePerm)
+	if err != nil {
+		return true
+	}
+
+	defer func() {
+		_ = file.Close()
+		_ = a.root.Remove(name)
+	}()
+
+	return !errors.Is(setXattr(file, probeAttribute, []byte("1")), errors.ErrUnsupported)
+}
+
+func (a Service) getAttribute(name string, file *os.File, attribute string) ([]byte, error) {
+	if !a.sidecar {
+		return getXattr(file, attribute)
+	}
+
+	attributes, err := a.readSidecar(name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := attributes[attribute]
+	if !ok {
+		return nil, errNoAttribute
+	}
+
+	return content, nil
+}
+
+func (a Service) setAttribute(name string, file *os.File, attribute string, content []byte) error {
+	if !a.sidecar {
+		return setXattr(file, attribute, content)
+	}
+
+	attributes, err := a.readSidecar(name)
+	if err != nil {
+		return err
+	}
+
+	attributes[attribute] = content
+
+	return a.writeSidecar(name, attributes)
+}
+
+func (a Service) removeAttribute(name string, file *os.File, attribute string) error {
+	if !a.sidecar {
+		return removeXattr(file, attribute)
+	}
+
+	attributes, err := a.readSidecar(name)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := attributes[attribute]; !ok {
+		return errNoAttribute
+	}
+
+	delete(attributes, attribute)
+
+	return a.writeSidecar(name, attributes)
+}
+
+func (a Service) readSidecar(name string) (map[string]json.RawMessage, error) {
+	attributes := make(map[string]json.RawMessage)
+
+	payload, err := a.root.ReadFile(sidecarPath(name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return attributes, nil
+		}
+
+		return nil, fmt.Errorf("read sidecar: %w", a.ConvertError(err))
+	}
+
+	if err = json.Unmarshal(payload, &attributes); err != nil {
+		return nil, fmt.Errorf("unmarshal sidecar: %w", err)
+	}
+
+	return attributes, nil
+}
+
+// writeSidecar replaces the sidecar atomically, an empty one being removed.
+func (a Service) writeSidecar(name string, attributes map[string]json.RawMessage) error {
+	if len(attributes) == 0 {
+		return a.removeSidecar(name)
+	}
+
+	payload, err := json.Marshal(attributes)
+	if err != nil {
+		return fmt.Errorf("marshal sidecar: %w", err)
+	}
+
+	sidecar := sidecarPath(name)
+
+	if err = a.mkdirAll(path.Dir(sidecar), a.dirPerm); err != nil {
+		return fmt.Errorf("create metadata directory: %w", a.ConvertError(err))
+	}
+
+	file, tempName, err := a.createTemp(path.Dir(sidecar), tempSidecarPrefix, a.filePerm)
+	if err != nil {
+		return fmt.Errorf("create sidecar: %w", err)
+	}
+
+	_, err = file.Write(payload)
+	if err = errors.Join(err, file.Close()); err == nil {
+		err = a.root.Rename(tempName, sidecar)
+	}
+
+	if err != nil {
+		return errors.Join(fmt.Errorf("write sidecar: %w", a.ConvertError(err)), a.removeTemp(tempName))
+	}
+
+	return nil
+}
+
+// removeSidecar removes the sidecar of a file or the sidecars of the content of a directory.
+func (a Service) removeSidecar(name string) error {
+	if !a.sidecar {
+		return nil
+	}
+
+	if err := a.root.RemoveAll(sidecarPath(name)); err != nil {
+		return fmt.Errorf("remove sidecar: %w", a.ConvertError(err))
+	}
+
+	return nil
+}
+
+// moveSidecar moves the sidecar of a renamed file or the sidecars of the content of a renamed directory.
+func (a Service) moveSidecar(oldName, newName string) error {
+	if !a.sidecar {
+		return nil
+	}
+
+	if err := a.removeSidecar(newName); err != nil {
+		return err
+	}
+
+	oldSidecar, newSidecar := sidecarPath(oldName), sidecarPath(newName)
+
+	if _, err := a.root.Lstat(oldSidecar); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("stat sidecar: %w", a.ConvertError(err))
+	}
+
+	if err := a.mkdirAll(path.Dir(newSidecar), a.dirPerm); err != nil {
+		return fmt.Errorf("create metadata directory: %w", a.ConvertError(err))
+	}
+
+	if err := a.root.Rename(oldSidecar, newSidecar); err != nil {
+		return fmt.Errorf("move sidecar: %w", a.ConvertError(err))
+	}
+
+	return nil
+}
+
+// copySidecar copies the sidecar of a file, the archived versions keeping the attributes of their content.
+func (a Service) copySidecar(oldName, newName string) error {
+	if !a.sidecar {
+		return nil
+	}
+
+	attributes, err := a.readSidecar(oldName)
+	if err != nil {
+		return err
+	}
+
+	return a.writeSidecar(newName, attributes)
+}
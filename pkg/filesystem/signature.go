@@ -0,0 +1,222 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const (
+	expiresParam   = "expires"
+	methodParam    = "method"
+	signatureParam = "signature"
+)
+
+var (
+	ErrSignatureDisabled = errors.New("signed url is not configured")
+	ErrInvalidSignature  = errors.New("signature is invalid")
+	ErrExpiredSignature  = errors.New("signature is expired")
+)
+
+func (a Service) SignedURL(_ context.Context, name, method string, expiry time.Duration) (string, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if a.signatureURL == nil {
+		return "", ErrSignatureDisabled
+	}
+
+	method = strings.ToUpper(method)
+	if method != http.MethodGet && method != http.MethodHead && method != http.MethodPut {
+		return "", fmt.Errorf("sign `%s` method: %w", method, errors.ErrUnsupported)
+	}
+
+	name = "/" + strings.TrimPrefix(name, "/")
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set(methodParam, method)
+	query.Set(expiresParam, expires)
+	query.Set(signatureParam, a.sign(method, name, expires))
+
+	output := *a.signatureURL
+	output.Path += name
+	output.RawQuery = query.Encode()
+
+	return output.String(), nil
+}
+
+// SignedHandler serves and receives the files for the URLs generated by SignedURL. It has to be mounted on the path of the signature URL.
+func (a Service) SignedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, err := a.verify(r)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrSignatureDisabled):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, ErrExpiredSignature), errors.Is(err, ErrInvalidSignature):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			a.serveSigned(w, r, name)
+		case http.MethodPut:
+			a.receiveSigned(w, r, name)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (a Service) serveSigned(w http.ResponseWriter, r *http.Request, name string) {
+	item, err := a.Stat(r.Context(), name)
+	if err != nil {
+		writeSignedError(w, err)
+		return
+	}
+
+	if item.IsDir() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	reader, err := a.ReadFrom(r.Context(), name)
+	if err != nil {
+		writeSignedError(w, err)
+		return
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	if len(item.ContentType) != 0 {
+		w.Header().Set("Content-Type", item.ContentType)
+	}
+
+	if len(item.ContentEncoding) != 0 {
+		w.Header().Set("Content-Encoding", item.ContentEncoding)
+	}
+
+	if len(item.CacheControl) != 0 {
+		w.Header().Set("Cache-Control", item.CacheControl)
+	}
+
+	http.ServeContent(w, r, item.Name(), item.Date, reader)
+}
+
+func (a Service) receiveSigned(w http.ResponseWriter, r *http.Request, name string) {
+	opts := model.WriteOpts{
+		ContentType:     r.Header.Get("Content-Type"),
+		ContentEncoding: r.Header.Get("Content-Encoding"),
+		CacheControl:    r.Header.Get("Cache-Control"),
+	}
+
+	if r.ContentLength > 0 {
+		opts.Size = r.ContentLength
+	}
+
+	if err := a.Mkdir(r.Context(), path.Dir(name), a.dirPerm); err != nil {
+		writeSignedError(w, err)
+		return
+	}
+
+	if err := a.WriteTo(r.Context(), name, r.Body, opts); err != nil {
+		writeSignedError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func writeSignedError(w http.ResponseWriter, err error) {
+	switch {
+	case model.IsNotExist(err):
+		w.WriteHeader(http.StatusNotFound)
+	case model.IsPermission(err):
+		w.WriteHeader(http.StatusForbidden)
+	case model.IsExist(err), model.IsConflict(err), model.IsIsDirectory(err), model.IsNotEmpty(err):
+		w.WriteHeader(http.StatusConflict)
+	case model.IsQuotaExceeded(err):
+		w.WriteHeader(http.StatusInsufficientStorage)
+	case model.IsUnavailable(err):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func (a Service) verify(r *http.Request) (string, error) {
+	if a.signatureURL == nil {
+		return "", ErrSignatureDisabled
+	}
+
+	name, ok := strings.CutPrefix(r.URL.Path, a.signatureURL.Path)
+	if !ok || len(name) == 0 {
+		return "", ErrInvalidSignature
+	}
+
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	query := r.URL.Query()
+
+	method := query.Get(methodParam)
+	if method != r.Method && (method != http.MethodGet || r.Method != http.MethodHead) {
+		return "", ErrInvalidSignature
+	}
+
+	expires := query.Get(expiresParam)
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(query.Get(signatureParam))
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	expected, _ := hex.DecodeString(a.sign(method, name, expires))
+	if !hmac.Equal(signature, expected) {
+		return "", ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return "", ErrExpiredSignature
+	}
+
+	return name, nil
+}
+
+func (a Service) sign(method, name, expires string) string {
+	hasher := hmac.New(sha256.New, a.signatureKey)
+
+	hasher.Write([]byte(method))
+	hasher.Write([]byte{'\n'})
+	hasher.Write([]byte(name))
+	hasher.Write([]byte{'\n'})
+	hasher.Write([]byte(expires))
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
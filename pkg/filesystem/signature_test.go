@@ -0,0 +1,99 @@
+package filesystem
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedHandler(t *testing.T) {
+	t.Parallel()
+
+	instance, err := New(t.TempDir(), WithSignature("http://localhost/files", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method string, expiry time.Duration) string {
+		output, err := instance.SignedURL(context.Background(), "/dir/file.txt", method, expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return output
+	}
+
+	type args struct {
+		method string
+		url    string
+		body   string
+	}
+
+	cases := map[string]struct {
+		args     args
+		want     int
+		wantBody string
+	}{
+		"upload": {
+			args{
+				method: http.MethodPut,
+				url:    sign(http.MethodPut, time.Minute),
+				body:   "signed content",
+			},
+			http.StatusCreated,
+			"",
+		},
+		"download": {
+			args{
+				method: http.MethodGet,
+				url:    sign(http.MethodGet, time.Minute),
+			},
+			http.StatusOK,
+			"signed content",
+		},
+		"wrong method": {
+			args{
+				method: http.MethodPut,
+				url:    sign(http.MethodGet, time.Minute),
+			},
+			http.StatusForbidden,
+			"",
+		},
+		"expired": {
+			args{
+				method: http.MethodGet,
+				url:    sign(http.MethodGet, -time.Minute),
+			},
+			http.StatusForbidden,
+			"",
+		},
+		"tampered": {
+			args{
+				method: http.MethodGet,
+				url:    strings.Replace(sign(http.MethodGet, time.Minute), "file.txt", "other.txt", 1),
+			},
+			http.StatusForbidden,
+			"",
+		},
+	}
+
+	for _, intention := range []string{"upload", "download", "wrong method", "expired", "tampered"} {
+		tc := cases[intention]
+
+		t.Run(intention, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			instance.SignedHandler().ServeHTTP(writer, httptest.NewRequest(tc.args.method, tc.args.url, strings.NewReader(tc.args.body)))
+
+			if got := writer.Code; got != tc.want {
+				t.Errorf("SignedHandler() = %d, want %d", got, tc.want)
+			}
+
+			if len(tc.wantBody) != 0 && writer.Body.String() != tc.wantBody {
+				t.Errorf("SignedHandler() = `%s`, want `%s`", writer.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
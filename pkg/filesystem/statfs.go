@@ -0,0 +1,19 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func freeBytes(file *os.File) (int64, error) {
+	var stat unix.Statfs_t
+
+	if err := unix.Fstatfs(int(file.Fd()), &stat); err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
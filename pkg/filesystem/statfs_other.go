@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+)
+
+func freeBytes(_ *os.File) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package filesystem
+
+import "errors"
+
+// syncDirectory flushes the entries of the directory, making a rename durable.
+func (a Service) syncDirectory(directory string) error {
+	dir, err := a.root.Open(directory)
+	if err != nil {
+		return a.ConvertError(err)
+	}
+
+	return a.ConvertError(errors.Join(dir.Sync(), dir.Close()))
+}
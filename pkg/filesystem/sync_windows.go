@@ -0,0 +1,6 @@
+package filesystem
+
+// syncDirectory does nothing, a directory can't be flushed on Windows.
+func (a Service) syncDirectory(_ string) error {
+	return nil
+}
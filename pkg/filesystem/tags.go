@@ -0,0 +1,92 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const tagsAttribute = "user.absto.tags"
+
+func (a Service) GetTags(_ context.Context, name string) (map[string]string, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := a.open(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return a.readTags(name, file)
+}
+
+func (a Service) SetTags(_ context.Context, name string, tags map[string]string) error {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := model.ValidTags(tags); err != nil {
+		return err
+	}
+
+	file, err := a.open(name, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return a.writeTags(name, file, tags)
+}
+
+func (a Service) DeleteTags(ctx context.Context, name string) error {
+	return a.SetTags(ctx, name, nil)
+}
+
+func (a Service) readTags(name string, file *os.File) (map[string]string, error) {
+	content, err := a.getAttribute(name, file, tagsAttribute)
+	if err != nil {
+		if errors.Is(err, errNoAttribute) || errors.Is(err, errors.ErrUnsupported) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+
+	var output map[string]string
+	if err = json.Unmarshal(content, &output); err != nil {
+		return nil, fmt.Errorf("unmarshal tags: %w", err)
+	}
+
+	return output, nil
+}
+
+func (a Service) writeTags(name string, file *os.File, tags map[string]string) error {
+	if len(tags) == 0 {
+		if err := a.removeAttribute(name, file, tagsAttribute); err != nil && !errors.Is(err, errNoAttribute) && !errors.Is(err, errors.ErrUnsupported) {
+			return fmt.Errorf("remove tags: %w", err)
+		}
+
+		return nil
+	}
+
+	payload, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("marshal tags: %w", err)
+	}
+
+	if err = a.setAttribute(name, file, tagsAttribute, payload); err != nil {
+		return fmt.Errorf("set tags: %w", err)
+	}
+
+	return nil
+}
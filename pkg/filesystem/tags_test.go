@@ -0,0 +1,58 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t)
+	ctx := context.Background()
+
+	if err := instance.WriteTo(ctx, "/file.txt", strings.NewReader("first"), model.WriteOpts{Tags: map[string]string{"retention": "30d"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := instance.GetTags(ctx, "/file.txt"); err != nil || !reflect.DeepEqual(got, map[string]string{"retention": "30d"}) {
+		t.Errorf("GetTags() = (%v, `%v`), want written tags", got, err)
+	}
+
+	if err := instance.WriteTo(ctx, "/file.txt", strings.NewReader("second"), model.WriteOpts{Mode: model.Append}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := instance.GetTags(ctx, "/file.txt"); !reflect.DeepEqual(got, map[string]string{"retention": "30d"}) {
+		t.Errorf("GetTags() = %v, want tags kept on append", got)
+	}
+
+	if err := instance.SetTags(ctx, "/file.txt", map[string]string{"classification": "internal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := instance.GetTags(ctx, "/file.txt"); !reflect.DeepEqual(got, map[string]string{"classification": "internal"}) {
+		t.Errorf("GetTags() = %v, want replaced tags", got)
+	}
+
+	if err := instance.SetTags(ctx, "/file.txt", map[string]string{"owner": "a&b"}); !errors.Is(err, model.ErrInvalidTags) {
+		t.Errorf("SetTags() = `%v`, want `%v`", err, model.ErrInvalidTags)
+	}
+
+	if err := instance.DeleteTags(ctx, "/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := instance.GetTags(ctx, "/file.txt"); len(got) != 0 {
+		t.Errorf("GetTags() = %v, want no tags", got)
+	}
+
+	if _, err := instance.GetTags(ctx, "/missing.txt"); !model.IsNotExist(err) {
+		t.Errorf("GetTags() = `%v`, want not exist", err)
+	}
+}
@@ -0,0 +1,400 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// uploadsDirectory holds a directory per upload, with its manifest and the parts stored as `<number>.<etag>`.
+const (
+	uploadsDirectory = internalDirectory + "/uploads"
+	uploadManifest   = "upload.json"
+	uploadIDLength   = 32
+	partNumberFormat = "%05d"
+)
+
+type upload struct {
+	Initiated time.Time         `json:"initiated"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Name      string            `json:"name"`
+	Metadata  metadata          `json:"metadata"`
+	Mode      model.WriteMode   `json:"mode,omitempty"`
+}
+
+func (u upload) writeOpts() model.WriteOpts {
+	return model.WriteOpts{
+		Metadata:        u.Metadata.Metadata,
+		ContentType:     u.Metadata.ContentType,
+		ContentEncoding: u.Metadata.ContentEncoding,
+		CacheControl:    u.Metadata.CacheControl,
+		Tags:            u.Tags,
+		Mode:            u.Mode,
+	}
+}
+
+func (a Service) CreateUpload(_ context.Context, name string, opts model.WriteOpts) (string, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if err := model.ValidTags(opts.Tags); err != nil {
+		return "", err
+	}
+
+	uploadID := newUploadID()
+	directory := uploadPath(uploadID)
+
+	if err := a.mkdirAll(directory, a.dirPerm); err != nil {
+		return "", fmt.Errorf("create upload directory: %w", a.ConvertError(err))
+	}
+
+	payload, err := json.Marshal(upload{
+		Initiated: time.Now(),
+		Name:      name,
+		Metadata:  newMetadata(opts),
+		Tags:      opts.Tags,
+		Mode:      opts.Mode,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal upload: %w", err)
+	}
+
+	if err = a.root.WriteFile(path.Join(directory, uploadManifest), payload, a.filePerm); err != nil {
+		return "", fmt.Errorf("write upload: %w", a.ConvertError(err))
+	}
+
+	return uploadID, nil
+}
+
+func (a Service) UploadPart(_ context.Context, name, uploadID string, partNumber int, reader io.Reader, size int64) (model.Part, error) {
+	if err := model.ValidPart(partNumber, size); err != nil {
+		return model.Part{}, err
+	}
+
+	directory, _, err := a.readUpload(name, uploadID)
+	if err != nil {
+		return model.Part{}, err
+	}
+
+	// The part is written aside to never expose a partial content when the network fails in the middle
+	pending, pendingName, err := a.createTemp(directory, tempPartPrefix, a.filePerm)
+	if err != nil {
+		return model.Part{}, fmt.Errorf("create part: %w", err)
+	}
+
+	defer func() { _ = a.root.Remove(pendingName) }()
+
+	hasher := md5.New()
+
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+
+	written, err := io.CopyBuffer(io.MultiWriter(pending, hasher), reader, *buf)
+	if err = errors.Join(err, pending.Close()); err != nil {
+		return model.Part{}, fmt.Errorf("write part: %w", a.ConvertError(err))
+	}
+
+	if written != size {
+		return model.Part{}, fmt.Errorf("part of %d bytes, %d received: %w", size, written, io.ErrUnexpectedEOF)
+	}
+
+	part := model.Part{
+		Number: partNumber,
+		ETag:   hex.EncodeToString(hasher.Sum(nil)),
+		Size:   written,
+		Date:   time.Now(),
+	}
+
+	previous, err := a.listParts(directory)
+	if err != nil {
+		return model.Part{}, err
+	}
+
+	if err = a.root.Rename(pendingName, path.Join(directory, partFilename(part))); err != nil {
+		return model.Part{}, fmt.Errorf("store part: %w", a.ConvertError(err))
+	}
+
+	for _, previousPart := range previous {
+		if previousPart.Number == partNumber && previousPart.ETag != part.ETag {
+			if err = a.root.Remove(path.Join(directory, partFilename(previousPart))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return model.Part{}, fmt.Errorf("remove previous part: %w", a.ConvertError(err))
+			}
+		}
+	}
+
+	return part, nil
+}
+
+func (a Service) ListParts(_ context.Context, name, uploadID string) ([]model.Part, error) {
+	directory, _, err := a.readUpload(name, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.listParts(directory)
+}
+
+func (a Service) CompleteUpload(ctx context.Context, name, uploadID string, parts []model.Part) error {
+	directory, content, err := a.readUpload(name, uploadID)
+	if err != nil {
+		return err
+	}
+
+	if len(parts) == 0 {
+		return fmt.Errorf("no part to complete: %w", model.ErrInvalidPart)
+	}
+
+	stored, err := a.listParts(directory)
+	if err != nil {
+		return err
+	}
+
+	filenames := make([]string, len(parts))
+
+	for index, part := range parts {
+		if index > 0 && part.Number <= parts[index-1].Number {
+			return fmt.Errorf("part %d is not in ascending order: %w", part.Number, model.ErrInvalidPart)
+		}
+
+		if !slices.ContainsFunc(stored, func(storedPart model.Part) bool {
+			return storedPart.Number == part.Number && storedPart.ETag == strings.Trim(part.ETag, `"`)
+		}) {
+			return fmt.Errorf("part %d with etag `%s` not found: %w", part.Number, part.ETag, model.ErrInvalidPart)
+		}
+
+		filenames[index] = path.Join(directory, partFilename(model.Part{Number: part.Number, ETag: strings.Trim(part.ETag, `"`)}))
+	}
+
+	reader := &partsReader{root: a.root, filenames: filenames}
+	defer func() { _ = reader.Close() }()
+
+	if err = a.WriteTo(ctx, name, reader, content.writeOpts()); err != nil {
+		return err
+	}
+
+	return a.ConvertError(a.root.RemoveAll(directory))
+}
+
+func (a Service) AbortUpload(_ context.Context, name, uploadID string) error {
+	directory, _, err := a.readUpload(name, uploadID)
+	if err != nil {
+		return err
+	}
+
+	return a.ConvertError(a.root.RemoveAll(directory))
+}
+
+func (a Service) ListUploads(_ context.Context, name string) ([]model.Upload, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(a.root.FS(), uploadPath(""))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, a.ConvertError(err)
+	}
+
+	// The uploads of the name itself or under it as a directory, the names only sharing its prefix being skipped
+	name = "/" + strings.TrimPrefix(name, "/")
+	prefix := strings.TrimSuffix(name, "/") + "/"
+
+	var uploads []model.Upload
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !validUploadID(entry.Name()) {
+			continue
+		}
+
+		content, err := a.readUploadManifest(uploadPath(entry.Name()))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if uploadName := "/" + strings.TrimPrefix(content.Name, "/"); uploadName != name && !strings.HasPrefix(uploadName, prefix) {
+			continue
+		}
+
+		uploads = append(uploads, model.Upload{
+			ID:        entry.Name(),
+			Name:      content.Name,
+			Initiated: content.Initiated,
+		})
+	}
+
+	return uploads, nil
+}
+
+// uploadPath returns the directory of the upload relative to the root directory.
+func uploadPath(uploadID string) string {
+	return rootName(path.Join(uploadsDirectory, uploadID))
+}
+
+// readUpload returns the directory and the manifest of the upload, checking that it belongs to the given name.
+func (a Service) readUpload(name, uploadID string) (string, upload, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return "", upload{}, err
+	}
+
+	if !validUploadID(uploadID) {
+		return "", upload{}, model.ErrNotExist(fmt.Errorf("upload `%s`", uploadID))
+	}
+
+	directory := uploadPath(uploadID)
+
+	content, err := a.readUploadManifest(directory)
+	if err != nil {
+		return "", upload{}, a.ConvertError(err)
+	}
+
+	if content.Name != name {
+		return "", upload{}, model.ErrNotExist(fmt.Errorf("upload `%s` of `%s`", uploadID, name))
+	}
+
+	return directory, content, nil
+}
+
+func (a Service) listParts(directory string) ([]model.Part, error) {
+	entries, err := fs.ReadDir(a.root.FS(), directory)
+	if err != nil {
+		return nil, a.ConvertError(err)
+	}
+
+	var parts []model.Part
+
+	for _, entry := range entries {
+		rawNumber, etag, ok := strings.Cut(entry.Name(), ".")
+		if !ok || entry.IsDir() || len(etag) != md5.Size*2 {
+			continue
+		}
+
+		number, err := strconv.Atoi(rawNumber)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		parts = append(parts, model.Part{
+			Number: number,
+			ETag:   etag,
+			Size:   info.Size(),
+			Date:   info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(parts, func(first, second model.Part) int {
+		return first.Number - second.Number
+	})
+
+	return parts, nil
+}
+
+func (a Service) readUploadManifest(directory string) (upload, error) {
+	var output upload
+
+	payload, err := a.root.ReadFile(path.Join(directory, uploadManifest))
+	if err != nil {
+		return output, err
+	}
+
+	if err = json.Unmarshal(payload, &output); err != nil {
+		return output, fmt.Errorf("unmarshal upload: %w", err)
+	}
+
+	return output, nil
+}
+
+func partFilename(part model.Part) string {
+	return fmt.Sprintf(partNumberFormat, part.Number) + "." + part.ETag
+}
+
+func newUploadID() string {
+	content := make([]byte, uploadIDLength/2)
+	_, _ = rand.Read(content)
+
+	return hex.EncodeToString(content)
+}
+
+func validUploadID(uploadID string) bool {
+	if len(uploadID) != uploadIDLength {
+		return false
+	}
+
+	_, err := hex.DecodeString(uploadID)
+
+	return err == nil
+}
+
+// partsReader reads the parts one after the other, opening a single file at a time.
+type partsReader struct {
+	root      *os.Root
+	current   *os.File
+	filenames []string
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.filenames) == 0 {
+				return 0, io.EOF
+			}
+
+			file, err := r.root.Open(r.filenames[0])
+			if err != nil {
+				return 0, fmt.Errorf("open part: %w", err)
+			}
+
+			r.current = file
+			r.filenames = r.filenames[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			err = r.current.Close()
+			r.current = nil
+
+			if n == 0 && err == nil {
+				continue
+			}
+		}
+
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+
+	return r.current.Close()
+}
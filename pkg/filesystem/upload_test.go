@@ -0,0 +1,99 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t)
+	ctx := context.Background()
+
+	uploadID, err := instance.CreateUpload(ctx, "/video.mp4", model.WriteOpts{ContentType: "video/mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for number, content := range map[int]string{2: "world", 1: "flaky"} {
+		if _, err = instance.UploadPart(ctx, "/video.mp4", uploadID, number, strings.NewReader(content), int64(len(content))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = instance.UploadPart(ctx, "/video.mp4", uploadID, 1, strings.NewReader("hel"), 6); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("UploadPart() = `%v`, want `%v`", err, io.ErrUnexpectedEOF)
+	}
+
+	if _, err = instance.UploadPart(ctx, "/video.mp4", uploadID, 1, strings.NewReader("hello "), 6); err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := instance.ListParts(ctx, "/video.mp4", uploadID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parts) != 2 || parts[0].Number != 1 || parts[0].Size != 6 || parts[1].Number != 2 {
+		t.Fatalf("ListParts() = %+v, want the two last parts", parts)
+	}
+
+	if items, _ := instance.List(ctx, "/"); len(items) != 0 {
+		t.Errorf("List() = %+v, want uploads to be hidden", items)
+	}
+
+	if err = instance.CompleteUpload(ctx, "/video.mp4", uploadID, parts); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readContent(t, instance, "/video.mp4"); got != "hello world" {
+		t.Errorf("CompleteUpload() content = `%s`, want `hello world`", got)
+	}
+
+	if item, _ := instance.Stat(ctx, "/video.mp4"); item.ContentType != "video/mp4" {
+		t.Errorf("CompleteUpload() content type = `%s`, want `video/mp4`", item.ContentType)
+	}
+
+	if _, err = instance.ListParts(ctx, "/video.mp4", uploadID); !model.IsNotExist(err) {
+		t.Errorf("ListParts() = `%v`, want not exist", err)
+	}
+}
+
+func TestAbortStaleUploads(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"/photos/a.jpg", "/photos/b.jpg", "/photos-old/d.jpg", "/videos/c.mp4"} {
+		if _, err := instance.CreateUpload(ctx, name, model.WriteOpts{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if uploads, err := instance.ListUploads(ctx, "/photos/"); err != nil || len(uploads) != 2 {
+		t.Errorf("ListUploads() = (%+v, `%v`), want 2 uploads", uploads, err)
+	}
+
+	if uploads, err := instance.ListUploads(ctx, "/photos"); err != nil || len(uploads) != 2 {
+		t.Errorf("ListUploads() = (%+v, `%v`), want the uploads of the directory only", uploads, err)
+	}
+
+	if uploads, err := instance.ListUploads(ctx, "/photos/a.jpg"); err != nil || len(uploads) != 1 {
+		t.Errorf("ListUploads() = (%+v, `%v`), want the upload of the file", uploads, err)
+	}
+
+	if count, err := model.AbortStaleUploads(ctx, instance, "/photos/", 0); err != nil || count != 2 {
+		t.Errorf("AbortStaleUploads() = (%d, `%v`), want 2", count, err)
+	}
+
+	if uploads, err := instance.ListUploads(ctx, "/"); err != nil || len(uploads) != 2 {
+		t.Errorf("ListUploads() = (%+v, `%v`), want the other uploads", uploads, err)
+	}
+}
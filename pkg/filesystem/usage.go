@@ -0,0 +1,222 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"runtime"
+	"sync"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var usageConcurrency = runtime.NumCPU() * 2
+
+// usageWalker reads the directories with a fixed number of workers, fed by a queue of the directories to visit.
+type usageWalker struct {
+	ctx     context.Context
+	err     error
+	cond    *sync.Cond
+	usage   model.Usage
+	service Service
+	queue   []usageDirectory
+	pending int
+	mutex   sync.Mutex
+}
+
+type usageDirectory struct {
+	name  string
+	child string
+}
+
+func (a Service) Usage(ctx context.Context, name string) (model.Usage, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return model.Usage{}, err
+	}
+
+	info, err := a.stat(name)
+	if err != nil {
+		return model.Usage{}, a.ConvertError(err)
+	}
+
+	root := rootName(name)
+	usage := model.NewUsage()
+
+	if !info.IsDir() {
+		usage.AddFile("", convertToItem(pathname(root), info))
+	} else {
+		walker := &usageWalker{
+			ctx:     ctx,
+			usage:   usage,
+			service: a,
+		}
+		walker.cond = sync.NewCond(&walker.mutex)
+
+		if err = walker.run(root); err != nil {
+			return model.Usage{}, err
+		}
+
+		usage = walker.usage
+	}
+
+	file, err := a.root.Open(root)
+	if err != nil {
+		return model.Usage{}, a.ConvertError(err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	if usage.FreeBytes, err = freeBytes(file); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return model.Usage{}, fmt.Errorf("free space: %w", err)
+	}
+
+	return usage, nil
+}
+
+func (w *usageWalker) run(root string) error {
+	var wg sync.WaitGroup
+
+	w.push(usageDirectory{name: root})
+
+	for range usageConcurrency {
+		wg.Go(w.work)
+	}
+
+	wg.Wait()
+
+	return w.err
+}
+
+func (w *usageWalker) work() {
+	for {
+		directory, ok := w.next()
+		if !ok {
+			return
+		}
+
+		w.visit(directory)
+		w.done()
+	}
+}
+
+// next waits for a directory to visit, false being returned once all of them have been visited.
+func (w *usageWalker) next() (usageDirectory, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for len(w.queue) == 0 && w.pending > 0 {
+		w.cond.Wait()
+	}
+
+	if len(w.queue) == 0 {
+		return usageDirectory{}, false
+	}
+
+	directory := w.queue[len(w.queue)-1]
+	w.queue = w.queue[:len(w.queue)-1]
+
+	return directory, true
+}
+
+func (w *usageWalker) push(directory usageDirectory) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.queue = append(w.queue, directory)
+	w.pending++
+
+	w.cond.Signal()
+}
+
+func (w *usageWalker) done() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.pending--; w.pending == 0 {
+		w.cond.Broadcast()
+	}
+}
+
+func (w *usageWalker) visit(directory usageDirectory) {
+	if w.failed() {
+		return
+	}
+
+	entries, err := fs.ReadDir(w.service.root.FS(), directory.name)
+	if err != nil {
+		w.fail(w.service.ConvertError(err))
+		return
+	}
+
+	local := model.NewUsage()
+
+	for _, entry := range entries {
+		entryName := path.Join(directory.name, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			w.fail(fmt.Errorf("read file metadata: %w", err))
+			return
+		}
+
+		info, ok := w.service.resolve(entryName, info)
+		if !ok {
+			continue
+		}
+
+		item := convertToItem(pathname(entryName), info)
+		if w.service.ignoreFn != nil && w.service.ignoreFn(item) {
+			continue
+		}
+
+		entryChild := directory.child
+		if len(entryChild) == 0 {
+			entryChild = entry.Name()
+		}
+
+		if item.IsDir() {
+			local.Directories++
+
+			// A resolved symlink is not walked into, for not counting its content twice
+			if entry.IsDir() {
+				w.push(usageDirectory{name: entryName, child: entryChild})
+			}
+
+			continue
+		}
+
+		local.AddFile(entryChild, item)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.usage.Merge(local)
+}
+
+func (w *usageWalker) failed() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err == nil {
+		w.err = w.ctx.Err()
+	}
+
+	return w.err != nil
+}
+
+func (w *usageWalker) fail(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err == nil {
+		w.err = err
+	}
+}
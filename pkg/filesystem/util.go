@@ -1,7 +1,8 @@
 package filesystem
 
 import (
-	"io"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path"
@@ -10,29 +11,131 @@ import (
 	"github.com/ViBiOh/absto/pkg/model"
 )
 
+// internalDirectory holds the directories managed by the service, each one being reserved and hidden from the
+// listings with the temporary files only when its feature is enabled.
+const internalDirectory = "/.absto"
+
+// internalDirectories returns the directories of the enabled features.
+func (a Service) internalDirectories() []string {
+	directories := []string{uploadsDirectory}
+
+	if a.versioning {
+		directories = append(directories, versionsDirectory)
+	}
+
+	if a.sidecar {
+		directories = append(directories, metadataDirectory)
+	}
+
+	return directories
+}
+
+func (a Service) isInternalItem(item model.Item) bool {
+	if item.Pathname == internalDirectory {
+		return a.onlyInternal()
+	}
+
+	return isTempName(item.Pathname) || a.isInternalName(item.Pathname)
+}
+
+// onlyInternal reports whether the internal directory holds nothing but the directories of the enabled features,
+// being hidden in that case.
+func (a Service) onlyInternal() bool {
+	entries, err := fs.ReadDir(a.root.FS(), rootName(internalDirectory))
+	if err != nil {
+		return true
+	}
+
+	for _, entry := range entries {
+		if !a.isInternalName(path.Join(internalDirectory, entry.Name())) && !isTempName(entry.Name()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a Service) isInternalName(name string) bool {
+	name = "/" + strings.TrimPrefix(name, "/")
+
+	for _, directory := range a.internalDirectories() {
+		if name == directory || strings.HasPrefix(name, directory+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a Service) hideInternal(ignoreFn func(model.Item) bool) func(model.Item) bool {
+	return func(item model.Item) bool {
+		return a.isInternalItem(item) || (ignoreFn != nil && ignoreFn(item))
+	}
+}
+
+func (a Service) cleanPath(name string) (string, error) {
+	name, err := a.pathPolicy.Clean(name)
+	if err != nil {
+		return "", err
+	}
+
+	if a.isInternalName(name) || hasTempSegment(name) {
+		return "", fmt.Errorf("reserved name `%s`: %w", name, model.ErrInvalidPath)
+	}
+
+	return name, a.checkDirectories(name)
+}
+
 func (a Service) getRelativePath(name string) string {
 	return strings.TrimPrefix(name, a.rootDirectory)
 }
 
-func (a Service) getFile(filename string, flags int) (*os.File, error) {
-	file, err := os.OpenFile(a.Path(filename), flags, getMode(filename))
-	return file, a.ConvertError(err)
+func (a Service) readDir(name string) ([]fs.DirEntry, error) {
+	if err := a.checkSymlinks(name); err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(a.root.FS(), rootName(name))
+
+	return entries, a.ConvertError(err)
 }
 
-func (a Service) getReadableFile(filename string) (model.ReadAtSeekCloser, error) {
-	return a.getFile(filename, model.ReadFlag)
+func (a Service) readEntry(dirname string, entry fs.DirEntry, withMetadata bool) (model.Item, bool, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return model.Item{}, false, fmt.Errorf("read file metadata: %w", err)
+	}
+
+	item, ok, err := a.item(path.Join(dirname, entry.Name()), info, withMetadata)
+	if err != nil {
+		return model.Item{}, false, fmt.Errorf("read file `%s`: %w", entry.Name(), err)
+	}
+
+	return item, ok, nil
 }
 
-func (a Service) getWritableFile(filename string) (io.WriteCloser, error) {
-	return a.getFile(filename, model.WriteFlag)
+func (a Service) getReadableFile(filename string) (model.ReadAtSeekCloser, error) {
+	return a.open(filename, model.ReadFlag, 0)
 }
 
-func getMode(name string) os.FileMode {
-	if strings.HasSuffix(name, "/") {
-		return model.DirectoryPerm
+// getWritableFile opens the file for writing, the configured mode and group being applied to a created file only.
+func (a Service) getWritableFile(filename string, mode model.WriteMode) (*os.File, error) {
+	flag := mode.Flag()
+
+	file, err := a.open(filename, flag|os.O_EXCL, a.filePerm)
+	if flag&os.O_EXCL == 0 && errors.Is(err, model.ErrExist) {
+		return a.open(filename, flag&^os.O_CREATE, 0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.setFileOwnership(file, a.filePerm); err != nil {
+		return nil, errors.Join(err, file.Close())
 	}
 
-	return model.RegularFilePerm
+	return file, nil
 }
 
 func convertToItem(pathname string, info fs.FileInfo) model.Item {
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"reflect"
 	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/ViBiOh/absto/pkg/model"
@@ -78,42 +80,6 @@ func TestGetRelativePath(t *testing.T) {
 	}
 }
 
-func TestGetMode(t *testing.T) {
-	t.Parallel()
-
-	type args struct {
-		name string
-	}
-
-	cases := map[string]struct {
-		args args
-		want os.FileMode
-	}{
-		"directory": {
-			args{
-				name: "/photos/",
-			},
-			model.DirectoryPerm,
-		},
-		"file": {
-			args{
-				name: "/photo.png",
-			},
-			model.RegularFilePerm,
-		},
-	}
-
-	for intention, tc := range cases {
-		t.Run(intention, func(t *testing.T) {
-			t.Parallel()
-
-			if got := getMode(tc.args.name); got != tc.want {
-				t.Errorf("getMode() = %d, want %d", got, tc.want)
-			}
-		})
-	}
-}
-
 func TestConvertToItem(t *testing.T) {
 	t.Parallel()
 
@@ -153,7 +119,7 @@ func TestConvertToItem(t *testing.T) {
 		t.Run(intention, func(t *testing.T) {
 			t.Parallel()
 
-			if got := convertToItem(tc.args.pathname, tc.args.info); got != tc.want {
+			if got := convertToItem(tc.args.pathname, tc.args.info); !reflect.DeepEqual(got, tc.want) {
 				t.Errorf("convertToItem() = %+v, want %+v", got, tc.want)
 			}
 		})
@@ -213,6 +179,87 @@ func TestConvertError(t *testing.T) {
 	}
 }
 
+func TestConvertErrorTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		args args
+		want error
+	}{
+		"permission": {
+			args{
+				err: &fs.PathError{Op: "open", Path: "/secret", Err: syscall.EACCES},
+			},
+			model.ErrPermission,
+		},
+		"not empty": {
+			args{
+				err: &os.LinkError{Op: "rename", Old: "/old", New: "/new", Err: syscall.ENOTEMPTY},
+			},
+			model.ErrNotEmpty,
+		},
+		"is directory": {
+			args{
+				err: &fs.PathError{Op: "open", Path: "/dir", Err: syscall.EISDIR},
+			},
+			model.ErrIsDirectory,
+		},
+		"not directory": {
+			args{
+				err: &fs.PathError{Op: "stat", Path: "/file/child", Err: syscall.ENOTDIR},
+			},
+			model.ErrNotDirectory,
+		},
+		"quota": {
+			args{
+				err: &fs.PathError{Op: "write", Path: "/file", Err: syscall.ENOSPC},
+			},
+			model.ErrQuotaExceeded,
+		},
+		"unavailable": {
+			args{
+				err: syscall.ESTALE,
+			},
+			model.ErrUnavailable,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got := Service{}.ConvertError(tc.args.err)
+
+			if !errors.Is(got, tc.want) {
+				t.Errorf("ConvertError() = `%v`, want `%v`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertErrorPath(t *testing.T) {
+	t.Parallel()
+
+	got := Service{rootDirectory: "/data"}.ConvertError(&fs.PathError{Op: "open", Path: "/data/secret.txt", Err: syscall.EACCES})
+
+	var pathErr *model.PathError
+	if !errors.As(got, &pathErr) {
+		t.Fatalf("ConvertError() = `%v`, want a path error", got)
+	}
+
+	if pathErr.Op != "open" || pathErr.Path != "/secret.txt" || pathErr.Backend != Name {
+		t.Errorf("ConvertError() = %+v, want open of `/secret.txt` on %s", pathErr, Name)
+	}
+
+	if want := "filesystem open `/secret.txt`: permission denied"; got.Error() != want {
+		t.Errorf("ConvertError() = `%s`, want `%s`", got, want)
+	}
+}
+
 func BenchmarkConvertToItem(b *testing.B) {
 	info, err := os.Stat("util_test.go")
 	if err != nil {
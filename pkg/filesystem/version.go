@@ -0,0 +1,453 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// versionsDirectory holds the prior versions of the files, with the same layout as the root directory.
+// A version is stored next to its siblings as `<name>.<versionID>`. The versionID is assigned on each write and stored
+// in an attribute of the content, its creation date ordering the versions and its random suffix telling apart those
+// created at the same date. A deletion is recorded as an empty `<name>.<versionID>.deleted` entry.
+const (
+	versionsDirectory  = internalDirectory + "/versions"
+	versionAttribute   = "user.absto.version"
+	versionIDLength    = 24
+	deleteMarkerSuffix = ".deleted"
+)
+
+func newVersionID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return fmt.Sprintf("%016x%s", uint64(time.Now().UnixNano()), hex.EncodeToString(suffix))
+}
+
+func validVersionID(versionID string) bool {
+	if len(versionID) != versionIDLength {
+		return false
+	}
+
+	_, err := hex.DecodeString(versionID)
+
+	return err == nil
+}
+
+// versionPath returns the name of the version relative to the root directory.
+func versionPath(name, versionID string) string {
+	return rootName(path.Join(versionsDirectory, name)) + "." + versionID
+}
+
+func (a Service) ListVersions(_ context.Context, name string) ([]model.Version, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []model.Version
+
+	info, err := a.stat(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, a.ConvertError(err)
+	}
+
+	var current string
+
+	if err == nil && !info.IsDir() {
+		if current, err = a.currentVersionID(name); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, model.Version{
+			ID:       current,
+			Date:     info.ModTime(),
+			Size:     info.Size(),
+			IsLatest: true,
+		})
+	}
+
+	entries, err := a.archivedVersions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []model.Version
+
+	for id, entry := range entries {
+		entryInfo, err := entry.Info()
+		if err != nil || id == current {
+			continue
+		}
+
+		archived = append(archived, model.Version{
+			ID:             id,
+			Date:           entryInfo.ModTime(),
+			Size:           entryInfo.Size(),
+			IsDeleteMarker: strings.HasSuffix(entry.Name(), deleteMarkerSuffix),
+		})
+	}
+
+	slices.SortFunc(archived, func(first, second model.Version) int {
+		return strings.Compare(second.ID, first.ID)
+	})
+
+	// A deleted file has its delete marker as the latest version
+	if len(versions) == 0 && len(archived) != 0 && archived[0].IsDeleteMarker {
+		archived[0].IsLatest = true
+	}
+
+	versions = append(versions, archived...)
+
+	if len(versions) == 0 {
+		return nil, model.ErrNotExist(fmt.Errorf("no version for `%s`", name))
+	}
+
+	return versions, nil
+}
+
+func (a Service) ReadVersion(_ context.Context, name, versionID string) (model.ReadAtSeekCloser, error) {
+	version, err := a.versionName(name, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := a.root.Open(version)
+	if err != nil {
+		return nil, a.ConvertError(err)
+	}
+
+	return file, nil
+}
+
+func (a Service) RestoreVersion(ctx context.Context, name, versionID string) error {
+	version, err := a.versionName(name, versionID)
+	if err != nil {
+		return err
+	}
+
+	if version == rootName(name) {
+		return nil
+	}
+
+	file, err := a.root.Open(version)
+	if err != nil {
+		return a.ConvertError(err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	content, err := a.readMetadata(version, file)
+	if err != nil {
+		return err
+	}
+
+	tags, err := a.readTags(version, file)
+	if err != nil {
+		return err
+	}
+
+	return a.WriteTo(ctx, name, file, model.WriteOpts{
+		Metadata:        content.Metadata,
+		ContentType:     content.ContentType,
+		ContentEncoding: content.ContentEncoding,
+		CacheControl:    content.CacheControl,
+		ModTime:         content.ModTime,
+		Tags:            tags,
+	})
+}
+
+// versionName returns the name of the given version relative to the root directory, being the current file or an archived one.
+func (a Service) versionName(name, versionID string) (string, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !validVersionID(versionID) {
+		return "", model.ErrNotExist(fmt.Errorf("version `%s` of `%s`", versionID, name))
+	}
+
+	if info, err := a.stat(name); err == nil && !info.IsDir() {
+		current, err := a.currentVersionID(name)
+		if err != nil {
+			return "", err
+		}
+
+		if versionID == current {
+			return rootName(name), nil
+		}
+	}
+
+	return versionPath(name, versionID), nil
+}
+
+// currentVersionID returns the version ID of the file, being empty for a file written without it.
+func (a Service) currentVersionID(name string) (string, error) {
+	file, err := a.open(name, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	content, err := a.getAttribute(name, file, versionAttribute)
+	if err != nil {
+		if errors.Is(err, errNoAttribute) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("get version: %w", err)
+	}
+
+	var versionID string
+	if err = json.Unmarshal(content, &versionID); err != nil || !validVersionID(versionID) {
+		return "", nil
+	}
+
+	return versionID, nil
+}
+
+// assignVersionID assigns a new version ID to the named file.
+func (a Service) assignVersionID(name string) (string, error) {
+	file, err := a.open(name, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return a.writeVersionID(name, file)
+}
+
+// writeVersionID assigns a new version ID to the content of the file.
+func (a Service) writeVersionID(name string, file *os.File) (string, error) {
+	versionID := newVersionID()
+
+	payload, err := json.Marshal(versionID)
+	if err != nil {
+		return "", fmt.Errorf("marshal version: %w", err)
+	}
+
+	if err = a.setAttribute(name, file, versionAttribute, payload); err != nil {
+		return "", fmt.Errorf("set version: %w", err)
+	}
+
+	return versionID, nil
+}
+
+// archivedVersions returns the entries of the archived versions and of the delete markers of the file by their ID.
+func (a Service) archivedVersions(name string) (map[string]fs.DirEntry, error) {
+	prefix := path.Base(versionPath(name, ""))
+
+	entries, err := fs.ReadDir(a.root.FS(), path.Dir(versionPath(name, "")))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, a.ConvertError(err)
+	}
+
+	versions := make(map[string]fs.DirEntry)
+
+	for _, entry := range entries {
+		id, ok := strings.CutPrefix(entry.Name(), prefix)
+		id = strings.TrimSuffix(id, deleteMarkerSuffix)
+
+		if ok && !entry.IsDir() && validVersionID(id) {
+			versions[id] = entry
+		}
+	}
+
+	return versions, nil
+}
+
+// archiveVersion links the current content of the file to the versions tree, returning the archived name or an empty string if there is nothing to archive.
+func (a Service) archiveVersion(name string) (string, error) {
+	info, err := a.stat(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", a.ConvertError(err)
+	}
+
+	if info.IsDir() {
+		return "", nil
+	}
+
+	versionID, err := a.currentVersionID(name)
+	if err != nil {
+		return "", err
+	}
+
+	// A file written without versioning gets its ID once archived
+	if len(versionID) == 0 {
+		if versionID, err = a.assignVersionID(name); err != nil {
+			return "", err
+		}
+	}
+
+	archived := versionPath(name, versionID)
+
+	if err = a.mkdirAll(path.Dir(archived), a.dirPerm); err != nil {
+		return "", fmt.Errorf("create versions directory: %w", a.ConvertError(err))
+	}
+
+	// The version is already archived by a previous write that failed after it
+	if err = a.root.Link(rootName(name), archived); errors.Is(err, fs.ErrExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("archive version: %w", a.ConvertError(err))
+	}
+
+	if err = a.copySidecar(rootName(name), archived); err != nil {
+		return "", errors.Join(err, a.removeArchived(archived))
+	}
+
+	return archived, nil
+}
+
+// deleteVersions archives the file or the files of the directory about to be deleted, recording a delete marker as
+// their latest version.
+func (a Service) deleteVersions(name string) error {
+	if !a.versioning {
+		return nil
+	}
+
+	// A symbolic link is removed without its target
+	if info, err := a.root.Lstat(rootName(name)); err != nil || isSymlink(info) {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return a.ConvertError(err)
+	}
+
+	return fs.WalkDir(a.root.FS(), rootName(name), func(itemName string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return a.ConvertError(err)
+		}
+
+		if a.isInternalName(itemName) || isTempName(itemName) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		if _, err = a.archiveVersion(pathname(itemName)); err != nil {
+			return err
+		}
+
+		return a.writeDeleteMarker(pathname(itemName))
+	})
+}
+
+// writeDeleteMarker records the deletion of the file as an empty entry of its versions.
+func (a Service) writeDeleteMarker(name string) error {
+	marker := versionPath(name, newVersionID()) + deleteMarkerSuffix
+
+	if err := a.mkdirAll(path.Dir(marker), a.dirPerm); err != nil {
+		return fmt.Errorf("create versions directory: %w", a.ConvertError(err))
+	}
+
+	file, err := a.root.OpenFile(marker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, a.filePerm)
+	if err != nil {
+		return fmt.Errorf("write delete marker: %w", a.ConvertError(err))
+	}
+
+	return a.ConvertError(file.Close())
+}
+
+// moveVersions moves the archived versions of a renamed file or of the content of a renamed directory, merging them
+// with the ones of the replaced name.
+func (a Service) moveVersions(oldName, newName string) error {
+	if !a.versioning {
+		return nil
+	}
+
+	entries, err := a.archivedVersions(oldName)
+	if err != nil {
+		return err
+	}
+
+	oldParent, newParent := path.Dir(versionPath(oldName, "")), path.Dir(versionPath(newName, ""))
+	oldPrefix, newPrefix := path.Base(versionPath(oldName, "")), path.Base(versionPath(newName, ""))
+
+	for _, entry := range entries {
+		suffix := strings.TrimPrefix(entry.Name(), oldPrefix)
+
+		if err = a.moveVersion(path.Join(oldParent, entry.Name()), path.Join(newParent, newPrefix+suffix)); err != nil {
+			return err
+		}
+	}
+
+	oldDirectory, newDirectory := rootName(path.Join(versionsDirectory, oldName)), rootName(path.Join(versionsDirectory, newName))
+
+	if info, err := a.root.Lstat(oldDirectory); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	if _, err = a.root.Lstat(newDirectory); errors.Is(err, fs.ErrNotExist) {
+		return a.moveVersion(oldDirectory, newDirectory)
+	}
+
+	return a.mergeVersions(oldDirectory, newDirectory)
+}
+
+// mergeVersions moves the archived versions of the old directory one by one into the existing new one.
+func (a Service) mergeVersions(oldDirectory, newDirectory string) error {
+	err := fs.WalkDir(a.root.FS(), oldDirectory, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		return a.moveVersion(name, path.Join(newDirectory, strings.TrimPrefix(name, oldDirectory)))
+	})
+	if err != nil {
+		return a.ConvertError(err)
+	}
+
+	if err = a.root.RemoveAll(oldDirectory); err != nil {
+		return fmt.Errorf("remove versions: %w", a.ConvertError(err))
+	}
+
+	return a.removeSidecar(oldDirectory)
+}
+
+func (a Service) moveVersion(oldName, newName string) error {
+	if err := a.mkdirAll(path.Dir(newName), a.dirPerm); err != nil {
+		return fmt.Errorf("create versions directory: %w", a.ConvertError(err))
+	}
+
+	if err := a.root.Rename(oldName, newName); err != nil {
+		return fmt.Errorf("move version: %w", a.ConvertError(err))
+	}
+
+	return a.moveSidecar(oldName, newName)
+}
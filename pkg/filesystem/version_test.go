@@ -0,0 +1,354 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	instance, err := New(t.TempDir(), WithVersioning())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	for _, content := range []string{"first", "second", "third"} {
+		if err = instance.WriteTo(ctx, "/doc.txt", strings.NewReader(content), model.WriteOpts{Tags: map[string]string{"content": content}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := instance.ListVersions(ctx, "/doc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(versions) != 3 || !versions[0].IsLatest || versions[1].IsLatest || versions[1].ID >= versions[0].ID || versions[2].ID >= versions[1].ID {
+		t.Fatalf("ListVersions() = %+v, want 3 versions from the latest", versions)
+	}
+
+	reader, err := instance.ReadVersion(ctx, "/doc.txt", versions[2].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := io.ReadAll(reader)
+	_ = reader.Close()
+
+	if err != nil || string(content) != "first" {
+		t.Errorf("ReadVersion() = (`%s`, `%v`), want `first`", content, err)
+	}
+
+	if err = instance.RestoreVersion(ctx, "/doc.txt", versions[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readContent(t, instance, "/doc.txt"); got != "first" {
+		t.Errorf("RestoreVersion() content = `%s`, want `first`", got)
+	}
+
+	if tags, _ := instance.GetTags(ctx, "/doc.txt"); tags["content"] != "first" {
+		t.Errorf("RestoreVersion() tags = %v, want restored tags", tags)
+	}
+
+	if versions, _ = instance.ListVersions(ctx, "/doc.txt"); len(versions) != 4 {
+		t.Errorf("ListVersions() = %d versions, want 4", len(versions))
+	}
+
+	items, err := instance.List(ctx, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].Pathname != "/doc.txt" {
+		t.Errorf("List() = %+v, want versions to be hidden", items)
+	}
+
+	if _, err = instance.ReadVersion(ctx, "/doc.txt", "unknown"); !model.IsNotExist(err) {
+		t.Errorf("ReadVersion() = `%v`, want not exist", err)
+	}
+}
+
+func TestVersionIDOnWrite(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(directory, "doc.txt"), []byte("unversioned"), model.RegularFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := New(directory, WithVersioning())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	for range 2 {
+		if versions, err := instance.ListVersions(ctx, "/doc.txt"); err != nil || len(versions) != 1 || len(versions[0].ID) != 0 {
+			t.Fatalf("ListVersions() = (%+v, `%v`), want a single version without ID", versions, err)
+		}
+	}
+
+	if _, err = instance.Stat(ctx, "/doc.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(directory, "doc.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = getXattr(file, versionAttribute)
+	_ = file.Close()
+
+	if !errors.Is(err, errNoAttribute) {
+		t.Errorf("getXattr() = `%v`, want no version assigned on read", err)
+	}
+
+	if err = instance.WriteTo(ctx, "/doc.txt", strings.NewReader("versioned"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := instance.ListVersions(ctx, "/doc.txt")
+	if err != nil || len(versions) != 2 || !validVersionID(versions[0].ID) || !validVersionID(versions[1].ID) {
+		t.Fatalf("ListVersions() = (%+v, `%v`), want IDs assigned on write", versions, err)
+	}
+
+	if got := readContent(t, instance, "/doc.txt"); got != "versioned" {
+		t.Errorf("WriteTo() content = `%s`, want `versioned`", got)
+	}
+}
+
+func TestVersionsLifecycle(t *testing.T) {
+	t.Parallel()
+
+	options := map[string][]ConfigOption{
+		"xattr":   {WithVersioning()},
+		"sidecar": {WithVersioning(), WithSidecarMetadata()},
+	}
+
+	for intention, opts := range options {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+
+			instance, err := New(directory, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			if err = instance.Mkdir(ctx, "/dir", model.DirectoryPerm); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, content := range []string{"first", "second"} {
+				if err = instance.WriteTo(ctx, "/dir/doc.txt", strings.NewReader(content), model.WriteOpts{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			versions, err := instance.ListVersions(ctx, "/dir/doc.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.UpdateDate(ctx, "/dir/doc.txt", time.Now().Add(-time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _ := instance.ListVersions(ctx, "/dir/doc.txt"); len(got) != 2 || got[0].ID != versions[0].ID || got[1].ID != versions[1].ID {
+				t.Errorf("ListVersions() = %+v, want IDs kept after a date update, from %+v", got, versions)
+			}
+
+			if err = instance.Rename(ctx, "/dir", "/moved"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := instance.ListVersions(ctx, "/moved/doc.txt"); err != nil || len(got) != 2 || got[1].ID != versions[1].ID {
+				t.Errorf("ListVersions() = (%+v, `%v`), want versions moved with the directory", got, err)
+			}
+
+			if err = instance.Rename(ctx, "/moved/doc.txt", "/doc.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := instance.ListVersions(ctx, "/doc.txt"); err != nil || len(got) != 2 || got[1].ID != versions[1].ID {
+				t.Errorf("ListVersions() = (%+v, `%v`), want versions moved with the file", got, err)
+			}
+
+			if _, err = instance.ListVersions(ctx, "/moved/doc.txt"); !model.IsNotExist(err) {
+				t.Errorf("ListVersions() = `%v`, want not exist", err)
+			}
+
+			if err = instance.WriteTo(ctx, "/other.txt", strings.NewReader("other"), model.WriteOpts{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.Rename(ctx, "/other.txt", "/doc.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := instance.ListVersions(ctx, "/doc.txt"); err != nil || len(got) != 3 || got[1].ID != versions[0].ID || got[2].ID != versions[1].ID {
+				t.Errorf("ListVersions() = (%+v, `%v`), want the history of the replaced file kept", got, err)
+			}
+
+			if err = instance.RemoveAll(ctx, "/doc.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := instance.ListVersions(ctx, "/doc.txt")
+			if err != nil || len(got) != 4 || !got[0].IsDeleteMarker || !got[0].IsLatest || got[1].IsDeleteMarker || got[1].IsLatest {
+				t.Fatalf("ListVersions() = (%+v, `%v`), want a delete marker as the latest version", got, err)
+			}
+
+			if _, err = instance.ReadVersion(ctx, "/doc.txt", got[0].ID); !model.IsNotExist(err) {
+				t.Errorf("ReadVersion() = `%v`, want not exist for a delete marker", err)
+			}
+
+			reader, err := instance.ReadVersion(ctx, "/doc.txt", got[1].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := io.ReadAll(reader)
+			_ = reader.Close()
+
+			if err != nil || string(content) != "other" {
+				t.Errorf("ReadVersion() = (`%s`, `%v`), want the deleted content", content, err)
+			}
+
+			if _, err = instance.Stat(ctx, "/doc.txt"); !model.IsNotExist(err) {
+				t.Errorf("Stat() = `%v`, want not exist", err)
+			}
+
+			if err = instance.WriteTo(ctx, "/moved/new.txt", strings.NewReader("new"), model.WriteOpts{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.RemoveAll(ctx, "/moved"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := instance.ListVersions(ctx, "/moved/new.txt"); err != nil || len(got) != 2 || !got[0].IsDeleteMarker || got[1].Size != 3 {
+				t.Errorf("ListVersions() = (%+v, `%v`), want the content of the removed directory kept", got, err)
+			}
+
+			if err = instance.Mkdir(ctx, "/dir", model.DirectoryPerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.WriteTo(ctx, "/dir/new.txt", strings.NewReader("newer"), model.WriteOpts{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = instance.Rename(ctx, "/dir", "/moved"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := instance.ListVersions(ctx, "/moved/new.txt"); err != nil || len(got) != 3 || !got[0].IsLatest || !got[1].IsDeleteMarker {
+				t.Errorf("ListVersions() = (%+v, `%v`), want the versions merged with the history of the new name", got, err)
+			}
+		})
+	}
+}
+
+func TestReservedNames(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options  []ConfigOption
+		reserved []string
+		allowed  []string
+	}{
+		"versioning": {
+			[]ConfigOption{WithVersioning()},
+			[]string{"/.absto/versions", "/.absto/uploads/upload.json", "/.absto-tmp-create-123", "/.absto-tmp-dir/doc.txt"},
+			[]string{"/.versions/doc.txt", "/.absto/versions-backup"},
+		},
+		"sidecar": {
+			[]ConfigOption{WithSidecarMetadata()},
+			[]string{"/.absto/metadata/doc.txt", "/.absto/uploads"},
+			[]string{"/.absto/versions/doc.txt"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := New(t.TempDir(), tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			for _, name := range tc.reserved {
+				if err = instance.WriteTo(ctx, name, strings.NewReader("content"), model.WriteOpts{}); !errors.Is(err, model.ErrInvalidPath) {
+					t.Errorf("WriteTo(`%s`) = `%v`, want invalid path", name, err)
+				}
+			}
+
+			for _, name := range tc.allowed {
+				if err = instance.Mkdir(ctx, path.Dir(name), model.DirectoryPerm); err != nil {
+					t.Fatal(err)
+				}
+
+				if err = instance.WriteTo(ctx, name, strings.NewReader("content"), model.WriteOpts{}); err != nil {
+					t.Errorf("WriteTo(`%s`) = `%v`", name, err)
+				}
+			}
+
+			items, err := instance.List(ctx, "/.absto")
+			if err != nil || len(items) == 0 {
+				t.Errorf("List() = (%+v, `%v`), want the allowed names listed", items, err)
+			}
+
+			for _, item := range items {
+				if slices.Contains(tc.reserved, item.Pathname) {
+					t.Errorf("List() = %+v, want reserved names hidden", items)
+				}
+			}
+
+			if items, _ = instance.List(ctx, "/"); !slices.ContainsFunc(items, func(item model.Item) bool { return item.Pathname == "/.absto" }) {
+				t.Errorf("List() = %+v, want the internal directory listed with user content", items)
+			}
+		})
+	}
+}
+
+func readContent(t *testing.T, instance Service, name string) string {
+	t.Helper()
+
+	reader, err := instance.ReadFrom(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,178 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+// walker walks a tree depth-first in lexical order, as fs.WalkDir does, with the service rules for the items.
+type walker struct {
+	ctx     context.Context
+	walkFn  func(model.Item) error
+	service Service
+	parents []fs.FileInfo
+	errs    []error
+	opts    model.WalkOpts
+}
+
+func (a Service) Walk(ctx context.Context, name string, walkFn func(model.Item) error) error {
+	return a.walk(ctx, name, model.WalkOpts{}, walkFn)
+}
+
+func (a Service) All(ctx context.Context, name string, opts model.WalkOpts) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		err := a.walk(ctx, name, opts, func(item model.Item) error {
+			if !yield(item, nil) {
+				return errStopIteration
+			}
+
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(model.Item{}, err)
+		}
+	}
+}
+
+func (a Service) walk(ctx context.Context, name string, opts model.WalkOpts, walkFn func(model.Item) error) error {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	info, err := a.stat(name)
+	if err == nil && isSymlink(info) {
+		// The walked name is read through its symlink, as the content of an exposed one
+		info, err = a.root.Stat(rootName(name))
+	}
+
+	if err != nil {
+		return a.ConvertError(err)
+	}
+
+	instance := walker{
+		ctx:     ctx,
+		walkFn:  walkFn,
+		service: a,
+		opts:    opts,
+	}
+
+	if err = instance.visit(rootName(name), info, 0); errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		err = nil
+	}
+
+	return errors.Join(append([]error{err}, instance.errs...)...)
+}
+
+// visit walks the entry read with a lstat, a fs.SkipDir being returned for skipping the remaining entries of its directory.
+func (w *walker) visit(name string, info fs.FileInfo, depth int) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	resolved, ok := w.service.resolve(name, info)
+	if !ok {
+		return nil
+	}
+
+	item := convertToItem(pathname(name), resolved)
+
+	var err error
+
+	if w.opts.WithMetadata {
+		if item, err = w.service.withMetadata(name, item); err != nil {
+			return w.fail(err)
+		}
+	}
+
+	if w.service.ignoreFn != nil && w.service.ignoreFn(item) {
+		return nil
+	}
+
+	if err = w.walkFn(item); err != nil {
+		if errors.Is(err, fs.SkipDir) && item.IsDir() {
+			return nil
+		}
+
+		return err
+	}
+
+	if !item.IsDir() || !w.descend(info, resolved, depth) {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(w.service.root.FS(), name)
+	if err != nil {
+		return w.fail(w.service.ConvertError(err))
+	}
+
+	w.parents = append(w.parents, resolved)
+	defer func() { w.parents = w.parents[:len(w.parents)-1] }()
+
+	for _, entry := range entries {
+		entryName := path.Join(name, entry.Name())
+
+		entryInfo, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			if err = w.fail(w.service.ConvertError(err)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err = w.visit(entryName, entryInfo, depth+1); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// descend checks the depth and the symlinks, a symlink to one of the parents never being walked into.
+func (w *walker) descend(info, resolved fs.FileInfo, depth int) bool {
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		return false
+	}
+
+	if !isSymlink(info) {
+		return true
+	}
+
+	if !w.opts.FollowSymlinks {
+		return false
+	}
+
+	for _, parent := range w.parents {
+		if os.SameFile(parent, resolved) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (w *walker) fail(err error) error {
+	if !w.opts.ContinueOnError {
+		return err
+	}
+
+	w.errs = append(w.errs, err)
+
+	return nil
+}
@@ -0,0 +1,436 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	nativeWatch = true
+
+	watchMask       = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DONT_FOLLOW | unix.IN_ONLYDIR
+	watchBufferSize = 64 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)
+	moveTimeout     = 50 * time.Millisecond
+)
+
+var errWatchOverflow = errors.New("watch queue overflow, events have been lost")
+
+type move struct {
+	fullpath string
+	isDir    bool
+}
+
+type watcher struct {
+	file    *os.File
+	target  string
+	paths   map[uint32]string
+	created map[string]struct{}
+	moves   map[uint32]move
+	output  chan<- model.Event
+	service Service
+	fd      int
+}
+
+func (a Service) Watch(ctx context.Context, name string) <-chan model.Event {
+	output := make(chan model.Event)
+
+	go func() {
+		defer close(output)
+
+		name, err := a.cleanPath(name)
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: err})
+			return
+		}
+
+		// inotify works on paths, the name is checked through the root before
+		if _, err = a.stat(name); err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: a.ConvertError(err)})
+			return
+		}
+
+		info, err := a.root.Lstat(rootName(name))
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: a.ConvertError(err)})
+			return
+		}
+
+		fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: fmt.Errorf("init inotify: %w", err)})
+			return
+		}
+
+		instance := watcher{
+			service: a,
+			fd:      fd,
+			file:    os.NewFile(uintptr(fd), "inotify"),
+			paths:   make(map[uint32]string),
+			created: make(map[string]struct{}),
+			moves:   make(map[uint32]move),
+			output:  output,
+		}
+
+		defer func() { _ = instance.file.Close() }()
+
+		// A file is watched through its parent directory, inotify refusing a directory watch on it
+		if fullpath := filepath.Clean(a.Path(name)); info.Mode().IsRegular() {
+			instance.target = fullpath
+			err = instance.add(filepath.Dir(fullpath))
+		} else {
+			err = instance.addRecursive(ctx, fullpath, false)
+		}
+
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: a.ConvertError(err)})
+			return
+		}
+
+		instance.run(ctx)
+	}()
+
+	return output
+}
+
+func (w *watcher) run(ctx context.Context) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = w.file.Close()
+		case <-done:
+		}
+	}()
+
+	buffer := make([]byte, watchBufferSize)
+
+	for {
+		// A move to the watched tree may be received in the next read, we wait a bit before considering it as a removal
+		var deadline time.Time
+		if len(w.moves) != 0 {
+			deadline = time.Now().Add(moveTimeout)
+		}
+
+		if err := w.file.SetReadDeadline(deadline); err != nil {
+			model.SendEvent(ctx, w.output, model.Event{Err: fmt.Errorf("set inotify deadline: %w", err)})
+			return
+		}
+
+		size, err := w.file.Read(buffer)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				if !w.flushMoves(ctx, 0) {
+					return
+				}
+
+				continue
+			}
+
+			if ctx.Err() == nil {
+				model.SendEvent(ctx, w.output, model.Event{Err: fmt.Errorf("read inotify: %w", err)})
+			}
+
+			return
+		}
+
+		var lastMove uint32
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= size; {
+			wd := binary.NativeEndian.Uint32(buffer[offset:])
+			mask := binary.NativeEndian.Uint32(buffer[offset+4:])
+			cookie := binary.NativeEndian.Uint32(buffer[offset+8:])
+			length := int(binary.NativeEndian.Uint32(buffer[offset+12:]))
+
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := strings.TrimRight(string(buffer[nameStart:nameStart+length]), "\x00")
+			offset = nameStart + length
+
+			if !w.handle(ctx, wd, mask, cookie, name) {
+				return
+			}
+
+			lastMove = 0
+			if mask&unix.IN_MOVED_FROM != 0 {
+				lastMove = cookie
+			}
+		}
+
+		if !w.flushMoves(ctx, lastMove) {
+			return
+		}
+	}
+}
+
+func (w *watcher) handle(ctx context.Context, wd, mask, cookie uint32, name string) bool {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		return model.SendEvent(ctx, w.output, model.Event{Err: errWatchOverflow})
+	}
+
+	if mask&unix.IN_IGNORED != 0 {
+		delete(w.paths, wd)
+		return true
+	}
+
+	dirname, ok := w.paths[wd]
+	if !ok {
+		return true
+	}
+
+	fullpath := filepath.Join(dirname, name)
+	isDir := mask&unix.IN_ISDIR != 0
+
+	// The siblings of a watched file are only followed for the moves into its place
+	if len(w.target) != 0 && isDir && fullpath != w.target {
+		return true
+	}
+
+	switch {
+	case mask&unix.IN_CREATE != 0:
+		if !isDir {
+			w.created[fullpath] = struct{}{}
+			return true
+		}
+
+		if err := w.addRecursive(ctx, fullpath, true); err != nil {
+			return model.SendEvent(ctx, w.output, model.Event{Err: w.service.ConvertError(err)})
+		}
+
+		return w.sendItem(ctx, model.Created, fullpath, "")
+
+	case mask&unix.IN_CLOSE_WRITE != 0:
+		eventType := model.Updated
+		if _, ok := w.created[fullpath]; ok {
+			delete(w.created, fullpath)
+			eventType = model.Created
+		}
+
+		return w.sendItem(ctx, eventType, fullpath, "")
+
+	case mask&unix.IN_DELETE != 0:
+		delete(w.created, fullpath)
+
+		return w.sendRemoved(ctx, fullpath, isDir)
+
+	case mask&unix.IN_MOVED_FROM != 0:
+		w.moves[cookie] = move{fullpath: fullpath, isDir: isDir}
+
+		return true
+
+	case mask&unix.IN_MOVED_TO != 0:
+		previous, ok := w.moves[cookie]
+		if !ok {
+			if isDir {
+				if err := w.addRecursive(ctx, fullpath, true); err != nil {
+					return model.SendEvent(ctx, w.output, model.Event{Err: w.service.ConvertError(err)})
+				}
+			}
+
+			return w.sendItem(ctx, model.Created, fullpath, "")
+		}
+
+		delete(w.moves, cookie)
+
+		// A write renames its temporary file into place
+		if !isDir && isTempName(previous.fullpath) {
+			eventType := model.Updated
+			if strings.HasPrefix(filepath.Base(previous.fullpath), tempCreatePrefix) {
+				eventType = model.Created
+			}
+
+			return w.sendItem(ctx, eventType, fullpath, "")
+		}
+
+		if isDir {
+			w.renamePaths(previous.fullpath, fullpath)
+		}
+
+		return w.sendItem(ctx, model.Renamed, fullpath, w.service.getRelativePath(previous.fullpath))
+	}
+
+	return true
+}
+
+// flushMoves sends a removal for the files moved outside of the watched tree, their counterpart being never received.
+func (w *watcher) flushMoves(ctx context.Context, except uint32) bool {
+	for cookie, previous := range w.moves {
+		if except != 0 && cookie == except {
+			continue
+		}
+
+		delete(w.moves, cookie)
+
+		if previous.isDir {
+			w.removePaths(previous.fullpath)
+		}
+
+		if !w.sendRemoved(ctx, previous.fullpath, previous.isDir) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (w *watcher) sendItem(ctx context.Context, eventType model.EventType, fullpath, oldPathname string) bool {
+	if !w.watched(fullpath) && (len(oldPathname) == 0 || !w.watched(w.service.Path(oldPathname))) {
+		return true
+	}
+
+	item := w.minimalItem(fullpath, false)
+
+	// The file may already be gone when the event is processed, the minimal item is sent in this case
+	if info, err := w.service.root.Lstat(rootName(item.Pathname)); err == nil {
+		resolved, ok, err := w.service.item(item.Pathname, info, true)
+		if err != nil {
+			return model.SendEvent(ctx, w.output, model.Event{Err: err})
+		}
+
+		if !ok {
+			return true
+		}
+
+		item = resolved
+	}
+
+	if w.service.ignoreFn != nil && w.service.ignoreFn(item) {
+		return true
+	}
+
+	return model.SendEvent(ctx, w.output, model.Event{Type: eventType, Item: item, OldPathname: oldPathname})
+}
+
+func (w *watcher) sendRemoved(ctx context.Context, fullpath string, isDir bool) bool {
+	if !w.watched(fullpath) {
+		return true
+	}
+
+	item := w.minimalItem(fullpath, isDir)
+
+	if w.service.ignoreFn != nil && w.service.ignoreFn(item) {
+		return true
+	}
+
+	return model.SendEvent(ctx, w.output, model.Event{Type: model.Removed, Item: item})
+}
+
+// watched reports whether the path is in the watched tree, a watched file being alone in it.
+func (w *watcher) watched(fullpath string) bool {
+	return len(w.target) == 0 || fullpath == w.target
+}
+
+func (w *watcher) minimalItem(fullpath string, isDir bool) model.Item {
+	pathname := w.service.getRelativePath(fullpath)
+
+	return model.Item{
+		ID:         model.ID(pathname),
+		NameValue:  filepath.Base(fullpath),
+		Pathname:   pathname,
+		IsDirValue: isDir,
+	}
+}
+
+// addRecursive watches the directory and its subdirectories, walked through the root directory. A symlinked directory
+// is not watched, inotify resolving paths outside of the root.
+func (w *watcher) addRecursive(ctx context.Context, root string, notify bool) error {
+	start := rootName(w.service.getRelativePath(root))
+
+	info, err := w.service.root.Lstat(start)
+	if err != nil {
+		return err
+	}
+
+	if isSymlink(info) {
+		if notify {
+			return nil
+		}
+
+		return &model.PathError{Op: "watch", Path: pathname(start), Backend: Name, Err: ErrSymlink}
+	}
+
+	return fs.WalkDir(w.service.root.FS(), start, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name != start {
+				return nil
+			}
+
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		fullpath := filepath.Join(w.service.rootDirectory, filepath.FromSlash(name))
+
+		item := convertToItem(pathname(name), info)
+		if w.service.ignoreFn != nil && w.service.ignoreFn(item) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		// A symlink is never walked into, a symlinked directory being notified as is
+		if !entry.IsDir() {
+			if notify && !w.sendItem(ctx, model.Created, fullpath, "") {
+				return ctx.Err()
+			}
+
+			return nil
+		}
+
+		if err = w.add(fullpath); err != nil {
+			return err
+		}
+
+		if notify && name != start && !w.sendItem(ctx, model.Created, fullpath, "") {
+			return ctx.Err()
+		}
+
+		return nil
+	})
+}
+
+func (w *watcher) add(fullpath string) error {
+	wd, err := unix.InotifyAddWatch(w.fd, fullpath, watchMask)
+	if err != nil {
+		return fmt.Errorf("watch `%s`: %w", pathname(w.service.getRelativePath(fullpath)), err)
+	}
+
+	w.paths[uint32(wd)] = fullpath
+
+	return nil
+}
+
+func (w *watcher) renamePaths(oldPath, newPath string) {
+	for wd, fullpath := range w.paths {
+		if fullpath == oldPath {
+			w.paths[wd] = newPath
+		} else if suffix, ok := strings.CutPrefix(fullpath, oldPath+"/"); ok {
+			w.paths[wd] = newPath + "/" + suffix
+		}
+	}
+}
+
+func (w *watcher) removePaths(oldPath string) {
+	for wd, fullpath := range w.paths {
+		if fullpath == oldPath || strings.HasPrefix(fullpath, oldPath+"/") {
+			_, _ = unix.InotifyRmWatch(w.fd, wd)
+			delete(w.paths, wd)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/dir/", "/ignored/").WithIgnoreFn(func(item model.Item) bool {
+		return strings.HasPrefix(item.Pathname, "/ignored")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := instance.Watch(ctx, "/")
+
+	// Wait for the watches to be registered
+	time.Sleep(100 * time.Millisecond)
+
+	cases := []struct {
+		action func() error
+		want   string
+	}{
+		{
+			func() error {
+				return instance.WriteTo(ctx, "/ignored/file.txt", strings.NewReader("ignored"), model.WriteOpts{})
+			},
+			"",
+		},
+		{
+			func() error {
+				return instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("created"), model.WriteOpts{})
+			},
+			"created /dir/file.txt",
+		},
+		{
+			func() error {
+				return instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("updated"), model.WriteOpts{})
+			},
+			"updated /dir/file.txt",
+		},
+		{
+			func() error { return instance.Rename(ctx, "/dir/file.txt", "/dir/renamed.txt") },
+			"renamed /dir/renamed.txt",
+		},
+		{
+			func() error { return instance.RemoveAll(ctx, "/dir/renamed.txt") },
+			"removed /dir/renamed.txt",
+		},
+	}
+
+	for _, tc := range cases {
+		if err := tc.action(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tc.want) == 0 {
+			continue
+		}
+
+		select {
+		case event := <-events:
+			if event.Err != nil {
+				t.Fatal(event.Err)
+			}
+
+			if got := event.Type.String() + " " + event.Item.Pathname; got != tc.want {
+				t.Errorf("Watch() = `%s`, want `%s`", got, tc.want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Watch() timeout, want `%s`", tc.want)
+		}
+	}
+
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	t.Parallel()
+
+	instance := newTestService(t, "/dir/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("created"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := instance.Watch(ctx, "/dir/file.txt")
+
+	// Wait for the watches to be registered
+	time.Sleep(100 * time.Millisecond)
+
+	actions := []func() error{
+		func() error {
+			return instance.WriteTo(ctx, "/dir/file.txt.bak", strings.NewReader("sibling"), model.WriteOpts{})
+		},
+		func() error { return instance.Mkdir(ctx, "/dir/file", model.DirectoryPerm) },
+		func() error {
+			return instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("updated"), model.WriteOpts{})
+		},
+		func() error { return instance.RemoveAll(ctx, "/dir/file.txt") },
+	}
+
+	for _, action := range actions {
+		if err := action(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []string{"updated /dir/file.txt", "removed /dir/file.txt"} {
+		select {
+		case event := <-events:
+			if event.Err != nil {
+				t.Fatal(event.Err)
+			}
+
+			if got := event.Type.String() + " " + event.Item.Pathname; got != want {
+				t.Errorf("Watch() = `%s`, want `%s`", got, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Watch() timeout, want `%s`", want)
+		}
+	}
+
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestWatchSymlink(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	outside := t.TempDir()
+
+	instance, err := New(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = instance.Mkdir(ctx, "/dir", model.DirectoryPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	for target, link := range map[string]string{outside: "outside", "dir": "inside"} {
+		if err = os.Symlink(target, filepath.Join(directory, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if event := <-instance.Watch(ctx, "/inside"); !errors.Is(event.Err, ErrSymlink) {
+		t.Errorf("Watch() = `%v`, want a symlink error", event.Err)
+	}
+
+	events := instance.Watch(ctx, "/")
+
+	// Wait for the watches to be registered
+	time.Sleep(100 * time.Millisecond)
+
+	if err = os.WriteFile(filepath.Join(outside, "file.txt"), []byte("outside"), model.RegularFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = instance.WriteTo(ctx, "/dir/file.txt", strings.NewReader("created"), model.WriteOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		if got := event.Type.String() + " " + event.Item.Pathname; event.Err != nil || got != "created /dir/file.txt" {
+			t.Errorf("Watch() = (`%s`, `%v`), want `created /dir/file.txt`", got, event.Err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Watch() timeout")
+	}
+
+	cancel()
+
+	for range events {
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"context"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const nativeWatch = false
+
+func (a Service) Watch(ctx context.Context, name string) <-chan model.Event {
+	output := make(chan model.Event)
+
+	go func() {
+		defer close(output)
+
+		name, err := a.cleanPath(name)
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: err})
+			return
+		}
+
+		model.Poll(ctx, model.DefaultPollInterval, func(ctx context.Context, walkFn func(model.Item) error) error {
+			return a.Walk(ctx, name, walkFn)
+		}, output)
+	}()
+
+	return output
+}
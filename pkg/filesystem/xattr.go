@@ -0,0 +1,47 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func getXattr(file *os.File, name string) ([]byte, error) {
+	size, err := unix.Fgetxattr(int(file.Fd()), name, nil)
+	if err != nil {
+		return nil, convertXattrError(err)
+	}
+
+	content := make([]byte, size)
+
+	size, err = unix.Fgetxattr(int(file.Fd()), name, content)
+	if err != nil {
+		return nil, convertXattrError(err)
+	}
+
+	return content[:size], nil
+}
+
+func setXattr(file *os.File, name string, content []byte) error {
+	return convertXattrError(unix.Fsetxattr(int(file.Fd()), name, content, 0))
+}
+
+func removeXattr(file *os.File, name string) error {
+	return convertXattrError(unix.Fremovexattr(int(file.Fd()), name))
+}
+
+func convertXattrError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errNoAttributeErrno):
+		return errNoAttribute
+	case errors.Is(err, unix.ENOTSUP):
+		return errors.ErrUnsupported
+	default:
+		return err
+	}
+}
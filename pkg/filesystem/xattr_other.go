@@ -0,0 +1,20 @@
+//go:build !linux && !darwin
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+)
+
+func getXattr(_ *os.File, _ string) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func setXattr(_ *os.File, _ string, _ []byte) error {
+	return errors.ErrUnsupported
+}
+
+func removeXattr(_ *os.File, _ string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,21 @@
+package model
+
+// Capabilities describes the behavior of a backend, for choosing a strategy at runtime.
+type Capabilities struct {
+	// MaxObjectSize is the maximum size of a single object in bytes, zero meaning unlimited.
+	MaxObjectSize int64
+	// AtomicRename is true when a rename is never observed half done.
+	AtomicRename bool
+	// PreservesMtime is true when UpdateDate changes the date of the item.
+	PreservesMtime bool
+	// RangeReads is true when ReadFrom supports seeking without reading the skipped content.
+	RangeReads bool
+	// ServerSideCopy is true when the content is copied without being transferred through the client.
+	ServerSideCopy bool
+	// Versioning is true when the prior versions are kept on overwrite and delete.
+	Versioning bool
+	// NativeWatch is true when Watch receives notifications instead of polling.
+	NativeWatch bool
+	// SignedURL is true when SignedURL generates URLs.
+	SignedURL bool
+}
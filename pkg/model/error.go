@@ -3,18 +3,58 @@ package model
 import (
 	"errors"
 	"fmt"
-	"regexp"
 )
 
 var (
-	errNotExists      = errors.New("not exists")
-	ErrRelativePath   = errors.New("name contains relatives paths")
-	ErrInvalidPath    = errors.New("name is invalid")
-	relativePathRegex = regexp.MustCompile(`(?m)(\/|^)\.\.(\/|$)`)
+	errNotExists     = errors.New("not exists")
+	ErrExist         = errors.New("already exists")
+	ErrConflict      = errors.New("precondition failed")
+	ErrPermission    = errors.New("permission denied")
+	ErrIsDirectory   = errors.New("is a directory")
+	ErrNotDirectory  = errors.New("not a directory")
+	ErrNotEmpty      = errors.New("directory not empty")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrUnavailable   = errors.New("storage unavailable")
+	ErrRelativePath  = errors.New("name contains relatives paths")
+	ErrInvalidPath   = errors.New("name is invalid")
 )
 
+// PathError records the operation and the path that caused an error on a backend, it unwraps to the sentinel of the taxonomy.
+type PathError struct {
+	Err     error
+	Op      string
+	Path    string
+	Backend string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s %s `%s`: %s", e.Backend, e.Op, e.Path, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+type sentinelError struct {
+	err      error
+	sentinel error
+}
+
+// WrapError returns an error with the message of err, matching both err and the sentinel.
+func WrapError(err, sentinel error) error {
+	return sentinelError{err: err, sentinel: sentinel}
+}
+
+func (e sentinelError) Error() string {
+	return e.err.Error()
+}
+
+func (e sentinelError) Unwrap() []error {
+	return []error{e.err, e.sentinel}
+}
+
 func ErrNotExist(err error) error {
-	return fmt.Errorf("%v: %w", err, errNotExists)
+	return fmt.Errorf("%w: %w", err, errNotExists)
 }
 
 func IsNotExist(err error) bool {
@@ -25,10 +65,43 @@ func IsNotExist(err error) bool {
 	return errors.Is(err, errNotExists)
 }
 
-func ValidPath(name string) error {
-	if relativePathRegex.MatchString(name) {
-		return ErrRelativePath
+func IsExist(err error) bool {
+	return isError(err, ErrExist)
+}
+
+// IsConflict reports an object changed by another writer since it was read, a condition on its version failing.
+func IsConflict(err error) bool {
+	return isError(err, ErrConflict)
+}
+
+func IsPermission(err error) bool {
+	return isError(err, ErrPermission)
+}
+
+func IsIsDirectory(err error) bool {
+	return isError(err, ErrIsDirectory)
+}
+
+func IsNotDirectory(err error) bool {
+	return isError(err, ErrNotDirectory)
+}
+
+func IsNotEmpty(err error) bool {
+	return isError(err, ErrNotEmpty)
+}
+
+func IsQuotaExceeded(err error) bool {
+	return isError(err, ErrQuotaExceeded)
+}
+
+func IsUnavailable(err error) bool {
+	return isError(err, ErrUnavailable)
+}
+
+func isError(err, target error) bool {
+	if err == nil {
+		return false
 	}
 
-	return nil
+	return errors.Is(err, target)
 }
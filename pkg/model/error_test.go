@@ -1,6 +1,9 @@
 package model
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestValidPath(t *testing.T) {
 	t.Parallel()
@@ -55,3 +58,50 @@ func TestValidPath(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapError(t *testing.T) {
+	t.Parallel()
+
+	native := errors.New("access denied by policy")
+
+	cases := map[string]struct {
+		is   func(error) bool
+		err  error
+		want bool
+	}{
+		"permission": {
+			IsPermission,
+			WrapError(native, ErrPermission),
+			true,
+		},
+		"path error": {
+			IsUnavailable,
+			&PathError{Op: "stat", Path: "/file", Backend: "test", Err: WrapError(native, ErrUnavailable)},
+			true,
+		},
+		"other sentinel": {
+			IsNotEmpty,
+			WrapError(native, ErrPermission),
+			false,
+		},
+		"nil": {
+			IsQuotaExceeded,
+			nil,
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.is(tc.err); got != tc.want {
+				t.Errorf("Is() = %t, want %t", got, tc.want)
+			}
+
+			if tc.err != nil && !errors.Is(tc.err, native) {
+				t.Errorf("WrapError() = `%v`, want to match the native error", tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const DefaultPollInterval = 30 * time.Second
+
+type EventType uint8
+
+const (
+	Created EventType = iota + 1
+	Updated
+	Removed
+	Renamed
+)
+
+func (e EventType) String() string {
+	switch e {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	case Renamed:
+		return "renamed"
+	default:
+		return "unknown"
+	}
+}
+
+type Event struct {
+	Err         error
+	Item        Item
+	OldPathname string
+	Type        EventType
+}
+
+func SendEvent(ctx context.Context, output chan<- Event, event Event) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case output <- event:
+		return true
+	}
+}
+
+// Poll sends the differences between successive snapshots of the walk function until the context is done.
+func Poll(ctx context.Context, interval time.Duration, walk func(context.Context, func(Item) error) error, output chan<- Event) {
+	previous, err := snapshot(ctx, walk)
+	if err != nil && !SendEvent(ctx, output, Event{Err: err}) {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := snapshot(ctx, walk)
+		if err != nil {
+			if !SendEvent(ctx, output, Event{Err: err}) {
+				return
+			}
+
+			continue
+		}
+
+		for _, event := range diffSnapshots(previous, current) {
+			if !SendEvent(ctx, output, event) {
+				return
+			}
+		}
+
+		previous = current
+	}
+}
+
+func snapshot(ctx context.Context, walk func(context.Context, func(Item) error) error) (map[string]Item, error) {
+	output := make(map[string]Item)
+
+	return output, walk(ctx, func(item Item) error {
+		output[item.Pathname] = item
+
+		return nil
+	})
+}
+
+func diffSnapshots(previous, current map[string]Item) []Event {
+	var output []Event
+
+	for pathname, item := range current {
+		previousItem, ok := previous[pathname]
+
+		switch {
+		case !ok:
+			output = append(output, Event{Type: Created, Item: item})
+		case !item.IsDir() && previousItem.String() != item.String():
+			output = append(output, Event{Type: Updated, Item: item})
+		}
+	}
+
+	for pathname, item := range previous {
+		if _, ok := current[pathname]; !ok {
+			output = append(output, Event{Type: Removed, Item: item})
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	type args struct {
+		previous map[string]Item
+		current  map[string]Item
+	}
+
+	cases := map[string]struct {
+		args args
+		want []string
+	}{
+		"empty": {
+			args{},
+			nil,
+		},
+		"changes": {
+			args{
+				previous: map[string]Item{
+					"/kept.txt":    {Pathname: "/kept.txt", SizeValue: 1, Date: now},
+					"/updated.txt": {Pathname: "/updated.txt", SizeValue: 1, Date: now},
+					"/removed.txt": {Pathname: "/removed.txt", SizeValue: 1, Date: now},
+					"/dir/":        {Pathname: "/dir/", IsDirValue: true, Date: now},
+				},
+				current: map[string]Item{
+					"/kept.txt":    {Pathname: "/kept.txt", SizeValue: 1, Date: now},
+					"/updated.txt": {Pathname: "/updated.txt", SizeValue: 2, Date: now},
+					"/created.txt": {Pathname: "/created.txt", SizeValue: 1, Date: now},
+					"/dir/":        {Pathname: "/dir/", IsDirValue: true, Date: now.Add(time.Second)},
+				},
+			},
+			[]string{"created /created.txt", "removed /removed.txt", "updated /updated.txt"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, event := range diffSnapshots(tc.args.previous, tc.args.current) {
+				got = append(got, event.Type.String()+" "+event.Item.Pathname)
+			}
+
+			sort.Strings(got)
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("diffSnapshots() = %v, want %v", got, tc.want)
+			}
+
+			for index := range got {
+				if got[index] != tc.want[index] {
+					t.Errorf("diffSnapshots() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
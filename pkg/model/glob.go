@@ -0,0 +1,130 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const globStar = "**"
+
+type Pattern struct {
+	raw      string
+	segments []string
+}
+
+// NewPattern parses a glob pattern, cleaned by the path policy of the backend for matching its names.
+func NewPattern(pattern string, pathPolicy PathPolicy) (Pattern, error) {
+	pattern, err := pathPolicy.Clean(pattern)
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	segments := splitSegments(pattern)
+
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return Pattern{}, fmt.Errorf("segment `%s`: %w", segment, err)
+		}
+	}
+
+	return Pattern{
+		raw:      pattern,
+		segments: segments,
+	}, nil
+}
+
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Prefix returns the longest literal prefix of the pattern, with a leading slash.
+func (p Pattern) Prefix() string {
+	raw := "/" + strings.TrimPrefix(p.raw, "/")
+
+	if index := strings.IndexAny(raw, `*?[\`); index != -1 {
+		return raw[:index]
+	}
+
+	return raw
+}
+
+// Dir returns the deepest directory that contains every match of the pattern.
+func (p Pattern) Dir() string {
+	prefix := p.Prefix()
+
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+func (p Pattern) Match(pathname string) bool {
+	return matchSegments(p.segments, splitSegments(pathname))
+}
+
+// MatchDir reports whether some descendant of the given directory may match the pattern.
+func (p Pattern) MatchDir(dirname string) bool {
+	return matchDirSegments(p.segments, splitSegments(dirname))
+}
+
+func splitSegments(pathname string) []string {
+	pathname = strings.Trim(pathname, "/")
+	if len(pathname) == 0 {
+		return nil
+	}
+
+	return strings.Split(pathname, "/")
+}
+
+// matchSegments matches the segments against the patterns, backtracking to the last globstar only, for not being
+// exponential with many of them.
+func matchSegments(patterns, segments []string) bool {
+	var pattern, segment int
+	star, starSegment := -1, 0
+
+	for segment < len(segments) {
+		switch {
+		case pattern < len(patterns) && patterns[pattern] == globStar:
+			star, starSegment = pattern, segment
+			pattern++
+		case pattern < len(patterns) && matchSegment(patterns[pattern], segments[segment]):
+			pattern++
+			segment++
+		case star != -1:
+			starSegment++
+			pattern, segment = star+1, starSegment
+		default:
+			return false
+		}
+	}
+
+	for pattern < len(patterns) && patterns[pattern] == globStar {
+		pattern++
+	}
+
+	return pattern == len(patterns)
+}
+
+func matchSegment(pattern, segment string) bool {
+	ok, _ := path.Match(pattern, segment)
+
+	return ok
+}
+
+func matchDirSegments(patterns, segments []string) bool {
+	if len(segments) == 0 {
+		return len(patterns) != 0
+	}
+
+	if len(patterns) == 0 {
+		return false
+	}
+
+	if patterns[0] == globStar {
+		return true
+	}
+
+	if !matchSegment(patterns[0], segments[0]) {
+		return false
+	}
+
+	return matchDirSegments(patterns[1:], segments[1:])
+}
@@ -0,0 +1,237 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPatternMatch(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		pattern  string
+		pathname string
+	}
+
+	cases := map[string]struct {
+		args args
+		want bool
+	}{
+		"literal": {
+			args{
+				pattern:  "/photos/summer.png",
+				pathname: "/photos/summer.png",
+			},
+			true,
+		},
+		"star": {
+			args{
+				pattern:  "/photos/*.png",
+				pathname: "/photos/summer.png",
+			},
+			true,
+		},
+		"star does not cross segment": {
+			args{
+				pattern:  "/photos/*.png",
+				pathname: "/photos/2024/summer.png",
+			},
+			false,
+		},
+		"question mark": {
+			args{
+				pattern:  "/photos/20?4.png",
+				pathname: "/photos/2024.png",
+			},
+			true,
+		},
+		"character class": {
+			args{
+				pattern:  "/photos/202[0-3].png",
+				pathname: "/photos/2024.png",
+			},
+			false,
+		},
+		"globstar zero segment": {
+			args{
+				pattern:  "/photos/**/*.png",
+				pathname: "/photos/summer.png",
+			},
+			true,
+		},
+		"globstar many segments": {
+			args{
+				pattern:  "/photos/**/*.png",
+				pathname: "/photos/2024/07/summer.png",
+			},
+			true,
+		},
+		"trailing globstar": {
+			args{
+				pattern:  "/photos/**",
+				pathname: "/photos/2024/07/",
+			},
+			true,
+		},
+		"no leading slash": {
+			args{
+				pattern:  "photos/*",
+				pathname: "/photos/summer.png",
+			},
+			true,
+		},
+		"globstar backtracking": {
+			args{
+				pattern:  "/photos/**/2024/*.png",
+				pathname: "/photos/2024/archive/2024/summer.png",
+			},
+			true,
+		},
+		"many globstars": {
+			args{
+				pattern:  "/" + strings.Repeat("**/", 20) + "missing",
+				pathname: strings.Repeat("/photos", 40),
+			},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := NewPattern(tc.args.pattern, DefaultPathPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := pattern.Match(tc.args.pathname); got != tc.want {
+				t.Errorf("Match() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPatternMatchDir(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		pattern string
+		dirname string
+	}
+
+	cases := map[string]struct {
+		args args
+		want bool
+	}{
+		"root": {
+			args{
+				pattern: "/photos/*.png",
+				dirname: "/",
+			},
+			true,
+		},
+		"matching dir": {
+			args{
+				pattern: "/photos/*.png",
+				dirname: "/photos",
+			},
+			true,
+		},
+		"other dir": {
+			args{
+				pattern: "/photos/*.png",
+				dirname: "/videos",
+			},
+			false,
+		},
+		"too deep": {
+			args{
+				pattern: "/photos/*.png",
+				dirname: "/photos/2024",
+			},
+			false,
+		},
+		"globstar": {
+			args{
+				pattern: "/photos/**/*.png",
+				dirname: "/photos/2024/07",
+			},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := NewPattern(tc.args.pattern, DefaultPathPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := pattern.MatchDir(tc.args.dirname); got != tc.want {
+				t.Errorf("MatchDir() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPatternPrefix(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		pattern string
+		want    string
+		wantDir string
+	}{
+		"literal": {
+			"/photos/summer.png",
+			"/photos/summer.png",
+			"/photos/",
+		},
+		"partial segment": {
+			"/photos/2024-*/*.png",
+			"/photos/2024-",
+			"/photos/",
+		},
+		"root": {
+			"**/*.png",
+			"/",
+			"/",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := NewPattern(tc.pattern, DefaultPathPolicy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, gotDir := pattern.Prefix(), pattern.Dir(); got != tc.want || gotDir != tc.wantDir {
+				t.Errorf("Prefix() = (`%s`, `%s`), want (`%s`, `%s`)", got, gotDir, tc.want, tc.wantDir)
+			}
+		})
+	}
+}
+
+func TestNewPatternPolicy(t *testing.T) {
+	t.Parallel()
+
+	pattern, err := NewPattern("/cafe\u0301/*.png", PathPolicy{NormalizeUnicode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pattern.Match("/caf\u00e9/summer.png") {
+		t.Errorf("Match() = false, want the pattern normalized")
+	}
+
+	if _, err = NewPattern("/photos/**/*.png", PathPolicy{MaxSegments: 2}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("NewPattern() = `%v`, want invalid path", err)
+	}
+}
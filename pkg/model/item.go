@@ -17,14 +17,20 @@ var (
 )
 
 type Item struct {
-	Date       time.Time   `json:"date"      msg:"date"`
-	ID         string      `json:"id"        msg:"id"`
-	NameValue  string      `json:"name"      msg:"name"`
-	Pathname   string      `json:"pathname"  msg:"pathname"`
-	Extension  string      `json:"extension" msg:"extension"`
-	SizeValue  int64       `json:"size"      msg:"size"`
-	FileMode   os.FileMode `json:"fileMode"  msg:"fileMode"`
-	IsDirValue bool        `json:"isDir"     msg:"isDir"`
+	Date            time.Time         `json:"date"                      msg:"date"`
+	OriginalDate    time.Time         `json:"originalDate,omitzero"     msg:"originalDate"`
+	Metadata        map[string]string `json:"metadata,omitempty"        msg:"metadata"`
+	ID              string            `json:"id"                        msg:"id"`
+	NameValue       string            `json:"name"                      msg:"name"`
+	Pathname        string            `json:"pathname"                  msg:"pathname"`
+	Extension       string            `json:"extension"                 msg:"extension"`
+	ContentType     string            `json:"contentType,omitempty"     msg:"contentType"`
+	ContentEncoding string            `json:"contentEncoding,omitempty" msg:"contentEncoding"`
+	CacheControl    string            `json:"cacheControl,omitempty"    msg:"cacheControl"`
+	Checksum        string            `json:"checksum,omitempty"        msg:"checksum"`
+	SizeValue       int64             `json:"size"                      msg:"size"`
+	FileMode        os.FileMode       `json:"fileMode"                  msg:"fileMode"`
+	IsDirValue      bool              `json:"isDir"                     msg:"isDir"`
 }
 
 func (i Item) Name() string {
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"iter"
+	"strings"
+)
+
+const (
+	DefaultListLimit = 1000
+
+	// A token holds the listed prefix and the last returned pathname, followed by their HMAC: a token is rejected
+	// for another listing or if it wasn't issued with the same key
+	tokenVersion   = "3:"
+	tokenSeparator = "\x00"
+	tokenSignature = "."
+	tokenKeyLength = 32
+)
+
+var ErrInvalidToken = errors.New("continuation token is invalid")
+
+type ListOpts struct {
+	StartAfter        string
+	ContinuationToken string
+	Limit             int
+	// WithMetadata reads the metadata of the items, for the backends not having them in their listing
+	WithMetadata bool
+}
+
+type Page struct {
+	NextToken string `json:"nextToken,omitempty" msg:"nextToken"`
+	Items     []Item `json:"items"               msg:"items"`
+}
+
+// Cursor returns the pathname after which the listing of the prefix resumes.
+func (o ListOpts) Cursor(key []byte, prefix string) (string, error) {
+	cursor := o.StartAfter
+
+	if len(o.ContinuationToken) != 0 {
+		pathname, err := DecodeToken(key, prefix, o.ContinuationToken)
+		if err != nil {
+			return "", err
+		}
+
+		cursor = max(cursor, pathname)
+	}
+
+	return cursor, nil
+}
+
+func (o ListOpts) PageSize() int {
+	if o.Limit <= 0 {
+		return DefaultListLimit
+	}
+
+	return o.Limit
+}
+
+// TokenKey returns the key signing the tokens, derived from the secret or random for the process if it's empty, the
+// tokens not surviving a restart in this case.
+func TokenKey(secret string) []byte {
+	if len(secret) != 0 {
+		return []byte(secret)
+	}
+
+	key := make([]byte, tokenKeyLength)
+	_, _ = rand.Read(key)
+
+	return key
+}
+
+func EncodeToken(key []byte, prefix, pathname string) string {
+	content := []byte(tokenVersion + prefix + tokenSeparator + pathname)
+
+	return base64.RawURLEncoding.EncodeToString(content) + tokenSignature + base64.RawURLEncoding.EncodeToString(signToken(key, content))
+}
+
+// DecodeToken returns the pathname of the token, a token of another prefix or with an invalid signature being invalid.
+func DecodeToken(key []byte, prefix, token string) (string, error) {
+	encodedContent, encodedSignature, ok := strings.Cut(token, tokenSignature)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	content, err := base64.RawURLEncoding.DecodeString(encodedContent)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, signToken(key, content)) {
+		return "", ErrInvalidToken
+	}
+
+	payload, ok := strings.CutPrefix(string(content), tokenVersion)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	tokenPrefix, pathname, ok := strings.Cut(payload, tokenSeparator)
+	if !ok || tokenPrefix != prefix || !strings.HasPrefix(pathname, prefix) || ValidPath(pathname) != nil {
+		return "", ErrInvalidToken
+	}
+
+	return pathname, nil
+}
+
+func signToken(key, content []byte) []byte {
+	hasher := hmac.New(sha256.New, key)
+	hasher.Write(content)
+
+	return hasher.Sum(nil)
+}
+
+// CollectPage reads a lexically sorted sequence of items of the prefix and returns the ones after the cursor, up to the page size.
+func CollectPage(items iter.Seq2[Item, error], key []byte, prefix, cursor string, limit int) (Page, error) {
+	var page Page
+
+	for item, err := range items {
+		if err != nil {
+			return Page{}, err
+		}
+
+		if len(cursor) != 0 && item.Pathname <= cursor {
+			continue
+		}
+
+		if len(page.Items) == limit {
+			page.NextToken = EncodeToken(key, prefix, page.Items[len(page.Items)-1].Pathname)
+
+			break
+		}
+
+		page.Items = append(page.Items, item)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"iter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("secret")
+
+func itemsOf(pathnames ...string) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		for _, pathname := range pathnames {
+			if !yield(Item{Pathname: pathname}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestDecodeToken(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		token string
+	}
+
+	cases := map[string]struct {
+		args    args
+		want    string
+		wantErr error
+	}{
+		"valid": {
+			args{
+				token: EncodeToken(testKey, "/photos", "/photos/summer.png"),
+			},
+			"/photos/summer.png",
+			nil,
+		},
+		"not base64": {
+			args{
+				token: "not a token!",
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"no version": {
+			args{
+				token: base64.RawURLEncoding.EncodeToString([]byte("/photos")) + "." + base64.RawURLEncoding.EncodeToString(signToken(testKey, []byte("/photos"))),
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"unsigned": {
+			args{
+				token: base64.RawURLEncoding.EncodeToString([]byte(tokenVersion + "/photos\x00/photos/summer.png")),
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"other key": {
+			args{
+				token: EncodeToken([]byte("other"), "/photos", "/photos/summer.png"),
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"forged": {
+			args{
+				token: base64.RawURLEncoding.EncodeToString([]byte(tokenVersion+"/photos\x00/photos/winter.png")) + "." + strings.SplitN(EncodeToken(testKey, "/photos", "/photos/summer.png"), ".", 2)[1],
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"other prefix": {
+			args{
+				token: EncodeToken(testKey, "/private", "/private/secret.png"),
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"outside prefix": {
+			args{
+				token: EncodeToken(testKey, "/photos", "/private/secret.png"),
+			},
+			"",
+			ErrInvalidToken,
+		},
+		"relative path": {
+			args{
+				token: EncodeToken(testKey, "/photos", "/photos/../../etc"),
+			},
+			"",
+			ErrInvalidToken,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := DecodeToken(testKey, "/photos", tc.args.token)
+			if got != tc.want || !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("DecodeToken() = (`%s`, `%s`), want (`%s`, `%s`)", got, gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCollectPage(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		items  iter.Seq2[Item, error]
+		cursor string
+		limit  int
+	}
+
+	cases := map[string]struct {
+		args args
+		want Page
+	}{
+		"single page": {
+			args{
+				items: itemsOf("/a", "/b"),
+				limit: 10,
+			},
+			Page{
+				Items: []Item{{Pathname: "/a"}, {Pathname: "/b"}},
+			},
+		},
+		"first page": {
+			args{
+				items: itemsOf("/a", "/b", "/c"),
+				limit: 2,
+			},
+			Page{
+				Items:     []Item{{Pathname: "/a"}, {Pathname: "/b"}},
+				NextToken: EncodeToken(testKey, "/", "/b"),
+			},
+		},
+		"exact page": {
+			args{
+				items: itemsOf("/a", "/b"),
+				limit: 2,
+			},
+			Page{
+				Items: []Item{{Pathname: "/a"}, {Pathname: "/b"}},
+			},
+		},
+		"cursor": {
+			args{
+				items:  itemsOf("/a", "/b", "/c"),
+				cursor: "/b",
+				limit:  2,
+			},
+			Page{
+				Items: []Item{{Pathname: "/c"}},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := CollectPage(tc.args.items, testKey, "/", tc.args.cursor, tc.args.limit)
+			if err != nil || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("CollectPage() = (%+v, `%s`), want %+v", got, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Limits of the names, fitting the 1024 bytes of a S3 key and the 255 bytes of a filename on most filesystems.
+const (
+	MaxPathLength    = 1024
+	MaxSegmentLength = 255
+)
+
+var DefaultPathPolicy = PathPolicy{
+	MaxLength:        MaxPathLength,
+	MaxSegmentLength: MaxSegmentLength,
+}
+
+// PathPolicy validates and normalizes the names given to a backend, a zero limit being unlimited.
+type PathPolicy struct {
+	MaxLength        int
+	MaxSegmentLength int
+	MaxSegments      int
+	NormalizeUnicode bool
+}
+
+// Clean returns the name with duplicate slashes and `.` segments removed, keeping its leading and trailing slashes.
+// Names with relative segments, invalid UTF-8, NUL bytes or control characters are rejected.
+func (p PathPolicy) Clean(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("invalid utf-8: %w", ErrInvalidPath)
+	}
+
+	for index, char := range name {
+		if unicode.IsControl(char) {
+			return "", fmt.Errorf("control character %U at byte %d: %w", char, index, ErrInvalidPath)
+		}
+	}
+
+	if p.NormalizeUnicode {
+		name = norm.NFC.String(name)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(name))
+
+	leading := strings.HasPrefix(name, "/")
+	var count int
+
+	for segment := range strings.SplitSeq(name, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return "", ErrRelativePath
+		}
+
+		if p.MaxSegmentLength > 0 && len(segment) > p.MaxSegmentLength {
+			return "", fmt.Errorf("segment of %d bytes, maximum is %d: %w", len(segment), p.MaxSegmentLength, ErrInvalidPath)
+		}
+
+		if count++; p.MaxSegments > 0 && count > p.MaxSegments {
+			return "", fmt.Errorf("more than %d segments: %w", p.MaxSegments, ErrInvalidPath)
+		}
+
+		if leading || builder.Len() != 0 {
+			builder.WriteByte('/')
+		}
+
+		builder.WriteString(segment)
+	}
+
+	if builder.Len() == 0 {
+		if leading {
+			return "/", nil
+		}
+
+		return "", nil
+	}
+
+	if strings.HasSuffix(name, "/") || strings.HasSuffix(name, "/.") {
+		builder.WriteByte('/')
+	}
+
+	output := builder.String()
+
+	if length := len(strings.TrimPrefix(output, "/")); p.MaxLength > 0 && length > p.MaxLength {
+		return "", fmt.Errorf("%d bytes, maximum is %d: %w", length, p.MaxLength, ErrInvalidPath)
+	}
+
+	return output, nil
+}
+
+func CleanPath(name string) (string, error) {
+	return DefaultPathPolicy.Clean(name)
+}
+
+func ValidPath(name string) error {
+	_, err := CleanPath(name)
+
+	return err
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		policy PathPolicy
+		name   string
+	}
+
+	cases := map[string]struct {
+		args    args
+		want    string
+		wantErr error
+	}{
+		"root": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/",
+			},
+			"/",
+			nil,
+		},
+		"empty": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "",
+			},
+			"",
+			nil,
+		},
+		"duplicate slashes": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "//photos///2024//",
+			},
+			"/photos/2024/",
+			nil,
+		},
+		"current segments": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "./photos/./image.png",
+			},
+			"photos/image.png",
+			nil,
+		},
+		"relative": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/photos/../../etc/passwd",
+			},
+			"",
+			ErrRelativePath,
+		},
+		"dots in name": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/photos/..hidden",
+			},
+			"/photos/..hidden",
+			nil,
+		},
+		"nul byte": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/image.png\x00.txt",
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"control character": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/image\n.png",
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"invalid utf-8": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/image\xff.png",
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"too long": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/" + strings.Repeat("a/", MaxPathLength/2) + "a",
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"max length": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/" + strings.Repeat("a/", MaxPathLength/2-1) + "ab",
+			},
+			"/" + strings.Repeat("a/", MaxPathLength/2-1) + "ab",
+			nil,
+		},
+		"segment too long": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/" + strings.Repeat("a", MaxSegmentLength+1),
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"too many segments": {
+			args{
+				policy: PathPolicy{MaxSegments: 2},
+				name:   "/a/b/c",
+			},
+			"",
+			ErrInvalidPath,
+		},
+		"not normalized": {
+			args{
+				policy: DefaultPathPolicy,
+				name:   "/cafe\u0301",
+			},
+			"/cafe\u0301",
+			nil,
+		},
+		"normalized": {
+			args{
+				policy: PathPolicy{NormalizeUnicode: true},
+				name:   "/cafe\u0301",
+			},
+			"/caf\u00e9",
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := tc.args.policy.Clean(tc.args.name)
+
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("Clean() error = `%v`, want `%v`", gotErr, tc.wantErr)
+			}
+
+			if got != tc.want {
+				t.Errorf("Clean() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
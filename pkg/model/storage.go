@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"io/fs"
+	"iter"
+	"net/http"
 	"os"
 	"time"
 )
@@ -12,12 +14,70 @@ const (
 	DirectoryPerm   = 0o700
 	RegularFilePerm = 0o600
 
-	ReadFlag  = os.O_RDONLY
-	WriteFlag = os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	ReadFlag           = os.O_RDONLY
+	WriteFlag          = os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	ExclusiveWriteFlag = os.O_RDWR | os.O_CREATE | os.O_EXCL
+	AppendWriteFlag    = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+)
+
+type WriteMode uint8
+
+const (
+	Overwrite WriteMode = iota
+	CreateExclusive
+	Append
+)
+
+func (m WriteMode) Flag() int {
+	switch m {
+	case CreateExclusive:
+		return ExclusiveWriteFlag
+	case Append:
+		return AppendWriteFlag
+	default:
+		return WriteFlag
+	}
+}
+
+// Durability defines what is flushed to the disk before a write returns, for the backends managing it.
+type Durability uint8
+
+const (
+	// DurabilityFull flushes the content and the directory entry.
+	DurabilityFull Durability = iota
+	// DurabilityContent flushes the content only, a crash may still lose the new file.
+	DurabilityContent
+	// DurabilityNone leaves the flush to the operating system, the write staying atomic.
+	DurabilityNone
 )
 
 type WriteOpts struct {
-	Size int64
+	// ModTime is the original modification time of the content, kept in the metadata by the backends supporting it
+	ModTime         time.Time
+	Metadata        map[string]string
+	Tags            map[string]string
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+	Size            int64
+	Mode            WriteMode
+	Durability      Durability
+}
+
+type WalkOpts struct {
+	// MaxDepth limits the depth of the items below the walked name, zero meaning unlimited
+	MaxDepth int
+	// FollowSymlinks walks into the directories resolved through a symlink, for the backends having them
+	FollowSymlinks bool
+	// ContinueOnError walks the remaining items when one can't be read, the errors being returned together at the end
+	ContinueOnError bool
+	// WithMetadata reads the metadata of the items, for the backends not having them in their listing
+	WithMetadata bool
+}
+
+type RemoveResult struct {
+	Err  error
+	Name string
 }
 
 type ReadAtSeekCloser interface {
@@ -25,6 +85,12 @@ type ReadAtSeekCloser interface {
 	io.ReaderAt
 }
 
+// ResumableReader reopens the version of the content it reads, a changed content failing with ErrConflict.
+type ResumableReader interface {
+	ReadAtSeekCloser
+	Resume(ctx context.Context) (ReadAtSeekCloser, error)
+}
+
 type File interface {
 	ReadAtSeekCloser
 	io.Writer
@@ -37,16 +103,40 @@ type Storage interface {
 	Mkdir(ctx context.Context, name string, perm os.FileMode) error
 	Rename(ctx context.Context, oldName, newName string) error
 	RemoveAll(ctx context.Context, name string) error
+	RemoveMany(ctx context.Context, names []string) ([]RemoveResult, error)
 
 	Enabled() bool
+	Close() error
+	Capabilities() Capabilities
 	Name() string
 	WithIgnoreFn(ignoreFn func(Item) bool) Storage
 	Path(name string) string
 
 	List(ctx context.Context, name string) ([]Item, error)
+	ListPage(ctx context.Context, name string, opts ListOpts) (Page, error)
+	Glob(ctx context.Context, pattern string) ([]Item, error)
 	WriteTo(ctx context.Context, name string, reader io.Reader, opts WriteOpts) error
 	ReadFrom(ctx context.Context, name string) (ReadAtSeekCloser, error)
 	Walk(ctx context.Context, name string, walkFn func(Item) error) error
+	Watch(ctx context.Context, name string) <-chan Event
+	SignedURL(ctx context.Context, name, method string, expiry time.Duration) (string, error)
+	SignedHandler() http.Handler
+	Usage(ctx context.Context, name string) (Usage, error)
+	GetTags(ctx context.Context, name string) (map[string]string, error)
+	SetTags(ctx context.Context, name string, tags map[string]string) error
+	DeleteTags(ctx context.Context, name string) error
+	ListVersions(ctx context.Context, name string) ([]Version, error)
+	ReadVersion(ctx context.Context, name, versionID string) (ReadAtSeekCloser, error)
+	RestoreVersion(ctx context.Context, name, versionID string) error
+	CreateUpload(ctx context.Context, name string, opts WriteOpts) (string, error)
+	UploadPart(ctx context.Context, name, uploadID string, partNumber int, reader io.Reader, size int64) (Part, error)
+	ListParts(ctx context.Context, name, uploadID string) ([]Part, error)
+	CompleteUpload(ctx context.Context, name, uploadID string, parts []Part) error
+	AbortUpload(ctx context.Context, name, uploadID string) error
+	ListUploads(ctx context.Context, name string) ([]Upload, error)
+
+	All(ctx context.Context, name string, opts WalkOpts) iter.Seq2[Item, error]
+	ListSeq(ctx context.Context, name string) iter.Seq2[Item, error]
 
 	UpdateDate(ctx context.Context, name string, date time.Time) error
 	ConvertError(err error) error
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Limits of the S3 object tagging, enforced on every backend to keep the tags portable.
+const (
+	MaxTagCount       = 10
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+var (
+	ErrInvalidTags = errors.New("tags are invalid")
+	tagRegex       = regexp.MustCompile(`^[a-zA-Z0-9+\-._:/@ =]*$`)
+)
+
+func ValidTags(tags map[string]string) error {
+	if len(tags) > MaxTagCount {
+		return fmt.Errorf("%d tags, maximum is %d: %w", len(tags), MaxTagCount, ErrInvalidTags)
+	}
+
+	for key, value := range tags {
+		if len(key) == 0 || utf8.RuneCountInString(key) > MaxTagKeyLength || !tagRegex.MatchString(key) {
+			return fmt.Errorf("key `%s`: %w", key, ErrInvalidTags)
+		}
+
+		if utf8.RuneCountInString(value) > MaxTagValueLength || !tagRegex.MatchString(value) {
+			return fmt.Errorf("value of `%s`: %w", key, ErrInvalidTags)
+		}
+	}
+
+	return nil
+}
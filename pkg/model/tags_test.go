@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidTags(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		tags map[string]string
+	}
+
+	cases := map[string]struct {
+		args args
+		want error
+	}{
+		"valid": {
+			args{
+				tags: map[string]string{"retention": "30d", "classification": "internal"},
+			},
+			nil,
+		},
+		"empty value": {
+			args{
+				tags: map[string]string{"archived": ""},
+			},
+			nil,
+		},
+		"empty key": {
+			args{
+				tags: map[string]string{"": "value"},
+			},
+			ErrInvalidTags,
+		},
+		"invalid character": {
+			args{
+				tags: map[string]string{"owner": "bob&alice"},
+			},
+			ErrInvalidTags,
+		},
+		"too long": {
+			args{
+				tags: map[string]string{"owner": strings.Repeat("a", MaxTagValueLength+1)},
+			},
+			ErrInvalidTags,
+		},
+		"too many": {
+			args{
+				tags: map[string]string{"a": "", "b": "", "c": "", "d": "", "e": "", "f": "", "g": "", "h": "", "i": "", "j": "", "k": ""},
+			},
+			ErrInvalidTags,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ValidTags(tc.args.tags); !errors.Is(got, tc.want) {
+				t.Errorf("ValidTags() = `%v`, want `%v`", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const MaxPartNumber = 10000
+
+var ErrInvalidPart = errors.New("part is invalid")
+
+type Upload struct {
+	Initiated time.Time `json:"initiated" msg:"initiated"`
+	ID        string    `json:"id"        msg:"id"`
+	Name      string    `json:"name"      msg:"name"`
+}
+
+type Part struct {
+	Date   time.Time `json:"date"   msg:"date"`
+	ETag   string    `json:"etag"   msg:"etag"`
+	Number int       `json:"number" msg:"number"`
+	Size   int64     `json:"size"   msg:"size"`
+}
+
+func ValidPart(number int, size int64) error {
+	if number < 1 || number > MaxPartNumber {
+		return fmt.Errorf("number %d is outside [1, %d]: %w", number, MaxPartNumber, ErrInvalidPart)
+	}
+
+	if size < 0 {
+		return fmt.Errorf("size is required: %w", ErrInvalidPart)
+	}
+
+	return nil
+}
+
+// AbortStaleUploads aborts the uploads under the given name initiated for longer than maxAge, returning how many were aborted.
+func AbortStaleUploads(ctx context.Context, storage Storage, name string, maxAge time.Duration) (int, error) {
+	uploads, err := storage.ListUploads(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("list uploads: %w", err)
+	}
+
+	var count int
+	var errs []error
+
+	for _, upload := range uploads {
+		if time.Since(upload.Initiated) < maxAge {
+			continue
+		}
+
+		if err = storage.AbortUpload(ctx, upload.Name, upload.ID); err != nil && !IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("abort upload `%s` of `%s`: %w", upload.ID, upload.Name, err))
+			continue
+		}
+
+		count++
+	}
+
+	return count, errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package model
+
+import "strings"
+
+type UsageEntry struct {
+	Bytes int64 `json:"bytes" msg:"bytes"`
+	Files int64 `json:"files" msg:"files"`
+}
+
+type Usage struct {
+	ByExtension map[string]UsageEntry `json:"byExtension"         msg:"byExtension"`
+	ByChild     map[string]UsageEntry `json:"byChild"             msg:"byChild"`
+	Bytes       int64                 `json:"bytes"               msg:"bytes"`
+	Files       int64                 `json:"files"               msg:"files"`
+	Directories int64                 `json:"directories"         msg:"directories"`
+	FreeBytes   int64                 `json:"freeBytes,omitempty" msg:"freeBytes"`
+}
+
+func NewUsage() Usage {
+	return Usage{
+		ByExtension: make(map[string]UsageEntry),
+		ByChild:     make(map[string]UsageEntry),
+	}
+}
+
+func (u *Usage) AddFile(child string, item Item) {
+	u.Bytes += item.SizeValue
+	u.Files++
+
+	u.ByExtension[item.Extension] = u.ByExtension[item.Extension].add(item.SizeValue, 1)
+
+	if len(child) != 0 {
+		u.ByChild[child] = u.ByChild[child].add(item.SizeValue, 1)
+	}
+}
+
+func (u *Usage) Merge(other Usage) {
+	u.Bytes += other.Bytes
+	u.Files += other.Files
+	u.Directories += other.Directories
+
+	for extension, entry := range other.ByExtension {
+		u.ByExtension[extension] = u.ByExtension[extension].add(entry.Bytes, entry.Files)
+	}
+
+	for child, entry := range other.ByChild {
+		u.ByChild[child] = u.ByChild[child].add(entry.Bytes, entry.Files)
+	}
+}
+
+func (e UsageEntry) add(bytes, files int64) UsageEntry {
+	e.Bytes += bytes
+	e.Files += files
+
+	return e
+}
+
+// UsageChild returns the name of the top-level child of root containing the given pathname.
+func UsageChild(root, pathname string) string {
+	relative := strings.TrimPrefix(pathname, Dirname(root))
+
+	if index := strings.Index(relative, "/"); index != -1 {
+		return relative[:index]
+	}
+
+	return relative
+}
@@ -0,0 +1,49 @@
+package model
+
+import "testing"
+
+func TestUsageChild(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		root     string
+		pathname string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"direct file": {
+			args{
+				root:     "/photos",
+				pathname: "/photos/summer.png",
+			},
+			"summer.png",
+		},
+		"nested file": {
+			args{
+				root:     "/photos/",
+				pathname: "/photos/2024/07/summer.png",
+			},
+			"2024",
+		},
+		"root": {
+			args{
+				root:     "/",
+				pathname: "/photos/summer.png",
+			},
+			"photos",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := UsageChild(tc.args.root, tc.args.pathname); got != tc.want {
+				t.Errorf("UsageChild() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
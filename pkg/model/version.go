@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type Version struct {
+	Date           time.Time `json:"date"           msg:"date"`
+	ID             string    `json:"id"             msg:"id"`
+	Size           int64     `json:"size"           msg:"size"`
+	IsLatest       bool      `json:"isLatest"       msg:"isLatest"`
+	IsDeleteMarker bool      `json:"isDeleteMarker" msg:"isDeleteMarker"`
+}
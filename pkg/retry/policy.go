@@ -0,0 +1,125 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+const (
+	DefaultAttempts   = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+type Config struct {
+	attempts   uint
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+type ConfigOption func(Config) Config
+
+// WithAttempts sets the maximum number of attempts of an operation, the first one included.
+func WithAttempts(attempts uint) ConfigOption {
+	return func(instance Config) Config {
+		instance.attempts = attempts
+
+		return instance
+	}
+}
+
+// WithBackoff sets the bounds of the exponential backoff between two attempts.
+func WithBackoff(minBackoff, maxBackoff time.Duration) ConfigOption {
+	return func(instance Config) Config {
+		instance.minBackoff = minBackoff
+		instance.maxBackoff = maxBackoff
+
+		return instance
+	}
+}
+
+// backoff returns the delay before the given retry, with a full jitter for spreading the retries of concurrent callers.
+func (c Config) backoff(retry uint) time.Duration {
+	delay := c.maxBackoff
+	if retry < 32 {
+		delay = min(c.minBackoff<<retry, c.maxBackoff)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay) + 1
+}
+
+// wait sleeps before the given retry, returning false if the context is done or its deadline is reached before.
+func (c Config) wait(ctx context.Context, retry uint) bool {
+	delay := c.backoff(retry)
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Retryable reports whether the error is a transient failure: throttling, server errors, connection resets or timeouts.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if model.IsUnavailable(err) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func do[T any](ctx context.Context, a Service, action func(context.Context) (T, error)) (T, error) {
+	var retry uint
+
+	for {
+		output, err := action(ctx)
+		if err == nil {
+			return output, nil
+		}
+
+		if isNonRetryable(err) || !a.retryable(err) {
+			return output, unwrapNonRetryable(err)
+		}
+
+		if retry++; retry >= a.config.attempts || !a.config.wait(ctx, retry-1) {
+			return output, err
+		}
+	}
+}
+
+func doErr(ctx context.Context, a Service, action func(context.Context) error) error {
+	_, err := do(ctx, a, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, action(ctx)
+	})
+
+	return err
+}
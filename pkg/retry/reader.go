@@ -0,0 +1,160 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var (
+	_ model.ReadAtSeekCloser = &resumableReader{}
+
+	errContentChanged = model.WrapError(errors.New("content changed since the first read"), model.ErrConflict)
+)
+
+// resumableReader reopens the stream after a transient failure and resumes it at the offset already read, the
+// reopened stream having to be the same version of the content.
+type resumableReader struct {
+	ctx         context.Context
+	reader      model.ReadAtSeekCloser
+	open        func(context.Context) (model.ReadAtSeekCloser, error)
+	service     Service
+	fingerprint string
+	offset      int64
+}
+
+func (a Service) resumable(ctx context.Context, open func(context.Context) (model.ReadAtSeekCloser, error)) (model.ReadAtSeekCloser, error) {
+	reader, err := do(ctx, a, open)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resumableReader{
+		ctx:         ctx,
+		reader:      reader,
+		open:        open,
+		service:     a,
+		fingerprint: fingerprint(reader),
+	}, nil
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	var retry uint
+
+	for {
+		n, err := r.reader.Read(p)
+		r.offset += int64(n)
+
+		if err == nil || errors.Is(err, io.EOF) || !r.service.retryable(err) {
+			return n, err
+		}
+
+		// The content already read is given back, the failure being handled on the next read
+		if n > 0 {
+			return n, nil
+		}
+
+		if retry++; retry >= r.service.config.attempts || !r.service.config.wait(r.ctx, retry-1) {
+			return 0, err
+		}
+
+		if reopenErr := r.reopen(); reopenErr != nil {
+			return 0, errors.Join(err, reopenErr)
+		}
+	}
+}
+
+func (r *resumableReader) ReadAt(p []byte, off int64) (int, error) {
+	var retry uint
+
+	for {
+		n, err := r.reader.ReadAt(p, off)
+		if err == nil || errors.Is(err, io.EOF) || !r.service.retryable(err) {
+			return n, err
+		}
+
+		if retry++; retry >= r.service.config.attempts || !r.service.config.wait(r.ctx, retry-1) {
+			return n, err
+		}
+
+		if reopenErr := r.reopen(); reopenErr != nil {
+			return n, errors.Join(err, reopenErr)
+		}
+	}
+}
+
+func (r *resumableReader) Seek(offset int64, whence int) (int64, error) {
+	position, err := r.reader.Seek(offset, whence)
+	if err == nil {
+		r.offset = position
+	}
+
+	return position, err
+}
+
+func (r *resumableReader) Close() error {
+	return r.reader.Close()
+}
+
+func (r *resumableReader) reopen() error {
+	reader, err := r.resume()
+	if err != nil {
+		return fmt.Errorf("reopen: %w", err)
+	}
+
+	if _, err = reader.Seek(r.offset, io.SeekStart); err != nil {
+		_ = reader.Close()
+
+		return fmt.Errorf("resume at %d: %w", r.offset, err)
+	}
+
+	r.reader = reader
+
+	return nil
+}
+
+// resume opens the content again, through the reader itself when it pins its version, by comparing the
+// fingerprints otherwise.
+func (r *resumableReader) resume() (model.ReadAtSeekCloser, error) {
+	if resumable, ok := r.reader.(model.ResumableReader); ok {
+		reader, err := resumable.Resume(r.ctx)
+		_ = r.reader.Close()
+
+		return reader, err
+	}
+
+	_ = r.reader.Close()
+
+	reader, err := r.open(r.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if fingerprint(reader) != r.fingerprint {
+		_ = reader.Close()
+
+		return nil, errContentChanged
+	}
+
+	return reader, nil
+}
+
+// fingerprint identifies the content of a file by its size and its modification date, being empty for a reader
+// without stat.
+func fingerprint(reader model.ReadAtSeekCloser) string {
+	file, ok := reader.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return ""
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
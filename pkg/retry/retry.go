@@ -0,0 +1,369 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var (
+	_ model.Storage = Service{}
+
+	errStopIteration = errors.New("stop iteration")
+)
+
+// Service retries the transient failures of the idempotent operations. Operations that cannot be safely replayed,
+// such as Rename or CompleteUpload, are attempted once.
+type Service struct {
+	storage model.Storage
+	config  Config
+}
+
+func New(storage model.Storage, options ...ConfigOption) model.Storage {
+	config := Config{
+		attempts:   DefaultAttempts,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+
+	for _, option := range options {
+		config = option(config)
+	}
+
+	if config.attempts <= 1 {
+		return storage
+	}
+
+	return Service{
+		storage: storage,
+		config:  config,
+	}
+}
+
+func (a Service) retryable(err error) bool {
+	return Retryable(a.storage.ConvertError(err))
+}
+
+func (a Service) Enabled() bool {
+	return a.storage.Enabled()
+}
+
+func (a Service) Close() error {
+	return a.storage.Close()
+}
+
+func (a Service) Capabilities() model.Capabilities {
+	return a.storage.Capabilities()
+}
+
+func (a Service) Name() string {
+	return a.storage.Name()
+}
+
+func (a Service) WithIgnoreFn(ignoreFn func(model.Item) bool) model.Storage {
+	return Service{
+		storage: a.storage.WithIgnoreFn(ignoreFn),
+		config:  a.config,
+	}
+}
+
+func (a Service) Path(name string) string {
+	return a.storage.Path(name)
+}
+
+func (a Service) Stat(ctx context.Context, name string) (model.Item, error) {
+	return do(ctx, a, func(ctx context.Context) (model.Item, error) {
+		return a.storage.Stat(ctx, name)
+	})
+}
+
+func (a Service) List(ctx context.Context, name string) ([]model.Item, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.Item, error) {
+		return a.storage.List(ctx, name)
+	})
+}
+
+func (a Service) ListPage(ctx context.Context, name string, opts model.ListOpts) (model.Page, error) {
+	return do(ctx, a, func(ctx context.Context) (model.Page, error) {
+		return a.storage.ListPage(ctx, name, opts)
+	})
+}
+
+func (a Service) Glob(ctx context.Context, pattern string) ([]model.Item, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.Item, error) {
+		return a.storage.Glob(ctx, pattern)
+	})
+}
+
+func (a Service) Usage(ctx context.Context, name string) (model.Usage, error) {
+	return do(ctx, a, func(ctx context.Context) (model.Usage, error) {
+		return a.storage.Usage(ctx, name)
+	})
+}
+
+func (a Service) GetTags(ctx context.Context, name string) (map[string]string, error) {
+	return do(ctx, a, func(ctx context.Context) (map[string]string, error) {
+		return a.storage.GetTags(ctx, name)
+	})
+}
+
+func (a Service) SetTags(ctx context.Context, name string, tags map[string]string) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.SetTags(ctx, name, tags)
+	})
+}
+
+func (a Service) DeleteTags(ctx context.Context, name string) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.DeleteTags(ctx, name)
+	})
+}
+
+func (a Service) ListVersions(ctx context.Context, name string) ([]model.Version, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.Version, error) {
+		return a.storage.ListVersions(ctx, name)
+	})
+}
+
+func (a Service) ReadVersion(ctx context.Context, name, versionID string) (model.ReadAtSeekCloser, error) {
+	return a.resumable(ctx, func(ctx context.Context) (model.ReadAtSeekCloser, error) {
+		return a.storage.ReadVersion(ctx, name, versionID)
+	})
+}
+
+func (a Service) RestoreVersion(ctx context.Context, name, versionID string) error {
+	return a.storage.RestoreVersion(ctx, name, versionID)
+}
+
+func (a Service) CreateUpload(ctx context.Context, name string, opts model.WriteOpts) (string, error) {
+	return a.storage.CreateUpload(ctx, name, opts)
+}
+
+func (a Service) UploadPart(ctx context.Context, name, uploadID string, partNumber int, reader io.Reader, size int64) (model.Part, error) {
+	rewind, ok := rewinder(reader)
+	if !ok {
+		return a.storage.UploadPart(ctx, name, uploadID, partNumber, reader, size)
+	}
+
+	return do(ctx, a, func(ctx context.Context) (model.Part, error) {
+		if err := rewind(); err != nil {
+			return model.Part{}, err
+		}
+
+		return a.storage.UploadPart(ctx, name, uploadID, partNumber, reader, size)
+	})
+}
+
+func (a Service) ListParts(ctx context.Context, name, uploadID string) ([]model.Part, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.Part, error) {
+		return a.storage.ListParts(ctx, name, uploadID)
+	})
+}
+
+func (a Service) CompleteUpload(ctx context.Context, name, uploadID string, parts []model.Part) error {
+	return a.storage.CompleteUpload(ctx, name, uploadID, parts)
+}
+
+func (a Service) AbortUpload(ctx context.Context, name, uploadID string) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.AbortUpload(ctx, name, uploadID)
+	})
+}
+
+func (a Service) ListUploads(ctx context.Context, name string) ([]model.Upload, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.Upload, error) {
+		return a.storage.ListUploads(ctx, name)
+	})
+}
+
+// WriteTo is retried only for an overwrite from a reader that can be rewound, an append or an exclusive creation not being idempotent.
+func (a Service) WriteTo(ctx context.Context, name string, reader io.Reader, opts model.WriteOpts) error {
+	rewind, ok := rewinder(reader)
+	if !ok || opts.Mode != model.Overwrite {
+		return a.storage.WriteTo(ctx, name, reader, opts)
+	}
+
+	return doErr(ctx, a, func(ctx context.Context) error {
+		if err := rewind(); err != nil {
+			return err
+		}
+
+		return a.storage.WriteTo(ctx, name, reader, opts)
+	})
+}
+
+func (a Service) ReadFrom(ctx context.Context, name string) (model.ReadAtSeekCloser, error) {
+	return a.resumable(ctx, func(ctx context.Context) (model.ReadAtSeekCloser, error) {
+		return a.storage.ReadFrom(ctx, name)
+	})
+}
+
+func (a Service) UpdateDate(ctx context.Context, name string, date time.Time) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.UpdateDate(ctx, name, date)
+	})
+}
+
+// Walk is retried as long as no item has been given to the walk function.
+func (a Service) Walk(ctx context.Context, name string, walkFn func(model.Item) error) error {
+	var started bool
+
+	return doErr(ctx, a, func(ctx context.Context) error {
+		err := a.storage.Walk(ctx, name, func(item model.Item) error {
+			started = true
+
+			return walkFn(item)
+		})
+
+		if started {
+			return nonRetryable(err)
+		}
+
+		return err
+	})
+}
+
+func (a Service) All(ctx context.Context, name string, opts model.WalkOpts) iter.Seq2[model.Item, error] {
+	return a.retrySeq(ctx, func(ctx context.Context) iter.Seq2[model.Item, error] {
+		return a.storage.All(ctx, name, opts)
+	})
+}
+
+func (a Service) ListSeq(ctx context.Context, name string) iter.Seq2[model.Item, error] {
+	return a.retrySeq(ctx, func(ctx context.Context) iter.Seq2[model.Item, error] {
+		return a.storage.ListSeq(ctx, name)
+	})
+}
+
+// retrySeq restarts the sequence on a transient failure as long as no item has been yielded.
+func (a Service) retrySeq(ctx context.Context, seq func(context.Context) iter.Seq2[model.Item, error]) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		var started bool
+
+		err := doErr(ctx, a, func(ctx context.Context) error {
+			for item, err := range seq(ctx) {
+				if err != nil {
+					if started {
+						return nonRetryable(err)
+					}
+
+					return err
+				}
+
+				started = true
+
+				if !yield(item, nil) {
+					return errStopIteration
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(model.Item{}, err)
+		}
+	}
+}
+
+func (a Service) Watch(ctx context.Context, name string) <-chan model.Event {
+	return a.storage.Watch(ctx, name)
+}
+
+func (a Service) SignedURL(ctx context.Context, name, method string, expiry time.Duration) (string, error) {
+	return do(ctx, a, func(ctx context.Context) (string, error) {
+		return a.storage.SignedURL(ctx, name, method, expiry)
+	})
+}
+
+func (a Service) SignedHandler() http.Handler {
+	return a.storage.SignedHandler()
+}
+
+func (a Service) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.Mkdir(ctx, name, perm)
+	})
+}
+
+func (a Service) Rename(ctx context.Context, oldName, newName string) error {
+	return a.storage.Rename(ctx, oldName, newName)
+}
+
+func (a Service) RemoveAll(ctx context.Context, name string) error {
+	return doErr(ctx, a, func(ctx context.Context) error {
+		return a.storage.RemoveAll(ctx, name)
+	})
+}
+
+func (a Service) RemoveMany(ctx context.Context, names []string) ([]model.RemoveResult, error) {
+	return do(ctx, a, func(ctx context.Context) ([]model.RemoveResult, error) {
+		return a.storage.RemoveMany(ctx, names)
+	})
+}
+
+func (a Service) ConvertError(err error) error {
+	return a.storage.ConvertError(err)
+}
+
+// rewinder returns a function seeking the reader back to its current offset, if the reader is seekable.
+func rewinder(reader io.Reader) (func() error, bool) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return nil, false
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, false
+	}
+
+	return func() error {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nonRetryable(err)
+		}
+
+		return nil
+	}, true
+}
+
+type nonRetryableError struct {
+	err error
+}
+
+func (e nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+func nonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return nonRetryableError{err: err}
+}
+
+func isNonRetryable(err error) bool {
+	var wrapped nonRetryableError
+
+	return errors.As(err, &wrapped)
+}
+
+func unwrapNonRetryable(err error) error {
+	var wrapped nonRetryableError
+	if errors.As(err, &wrapped) {
+		return wrapped.err
+	}
+
+	return err
+}
@@ -0,0 +1,351 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var errTransient = model.WrapError(errors.New("slow down"), model.ErrUnavailable)
+
+type flakyStorage struct {
+	model.Storage
+	calls    *atomic.Int32
+	content  string
+	failures int32
+}
+
+func newFlakyStorage(failures int32) flakyStorage {
+	return flakyStorage{
+		calls:    &atomic.Int32{},
+		content:  "hello world",
+		failures: failures,
+	}
+}
+
+func (f flakyStorage) fail() error {
+	if f.calls.Add(1) <= f.failures {
+		return errTransient
+	}
+
+	return nil
+}
+
+func (f flakyStorage) ConvertError(err error) error {
+	return err
+}
+
+func (f flakyStorage) Stat(_ context.Context, name string) (model.Item, error) {
+	if err := f.fail(); err != nil {
+		return model.Item{}, err
+	}
+
+	return model.Item{Pathname: name}, nil
+}
+
+func (f flakyStorage) Rename(_ context.Context, _, _ string) error {
+	return f.fail()
+}
+
+func (f flakyStorage) WriteTo(_ context.Context, _ string, reader io.Reader, _ model.WriteOpts) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if err = f.fail(); err != nil {
+		return err
+	}
+
+	if string(content) != f.content {
+		return errors.New("content not rewound")
+	}
+
+	return nil
+}
+
+func (f flakyStorage) ReadFrom(_ context.Context, _ string) (model.ReadAtSeekCloser, error) {
+	return &flakyReader{Reader: strings.NewReader(f.content), storage: f}, nil
+}
+
+// flakyReader fails after each chunk of 4 bytes until the failures are exhausted.
+type flakyReader struct {
+	*strings.Reader
+	storage flakyStorage
+	read    int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.read >= 4 {
+		if err := r.storage.fail(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.Reader.Read(p[:min(len(p), 4)])
+	r.read += n
+
+	return n, err
+}
+
+func (r *flakyReader) Close() error {
+	return nil
+}
+
+func newTestService(storage model.Storage) Service {
+	return New(storage, WithAttempts(3), WithBackoff(time.Millisecond, 2*time.Millisecond)).(Service)
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		action    func(Service) error
+		failures  int32
+		wantCalls int32
+		wantErr   error
+	}{
+		"recovered": {
+			func(instance Service) error {
+				_, err := instance.Stat(context.Background(), "/file")
+				return err
+			},
+			2,
+			3,
+			nil,
+		},
+		"exhausted": {
+			func(instance Service) error {
+				_, err := instance.Stat(context.Background(), "/file")
+				return err
+			},
+			5,
+			3,
+			model.ErrUnavailable,
+		},
+		"not idempotent": {
+			func(instance Service) error {
+				return instance.Rename(context.Background(), "/old", "/new")
+			},
+			1,
+			1,
+			model.ErrUnavailable,
+		},
+		"seekable write": {
+			func(instance Service) error {
+				return instance.WriteTo(context.Background(), "/file", strings.NewReader("hello world"), model.WriteOpts{})
+			},
+			1,
+			2,
+			nil,
+		},
+		"stream write": {
+			func(instance Service) error {
+				return instance.WriteTo(context.Background(), "/file", io.MultiReader(strings.NewReader("hello world")), model.WriteOpts{})
+			},
+			1,
+			1,
+			model.ErrUnavailable,
+		},
+		"append write": {
+			func(instance Service) error {
+				return instance.WriteTo(context.Background(), "/file", strings.NewReader("hello world"), model.WriteOpts{Mode: model.Append})
+			},
+			1,
+			1,
+			model.ErrUnavailable,
+		},
+		"deadline": {
+			func(instance Service) error {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+				defer cancel()
+
+				instance.config.minBackoff = time.Second
+				instance.config.maxBackoff = time.Second
+
+				_, err := instance.Stat(ctx, "/file")
+				return err
+			},
+			2,
+			1,
+			model.ErrUnavailable,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			storage := newFlakyStorage(tc.failures)
+
+			if err := tc.action(newTestService(storage)); !errors.Is(err, tc.wantErr) {
+				t.Errorf("action() = `%v`, want `%v`", err, tc.wantErr)
+			}
+
+			if got := storage.calls.Load(); got != tc.wantCalls {
+				t.Errorf("action() = %d calls, want %d", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestReadFromResume(t *testing.T) {
+	t.Parallel()
+
+	storage := newFlakyStorage(2)
+
+	reader, err := newTestService(storage).ReadFrom(context.Background(), "/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "hello world" {
+		t.Errorf("ReadFrom() = `%s`, want `hello world`", content)
+	}
+}
+
+type fileInfo struct {
+	modTime time.Time
+	size    int64
+}
+
+func (i fileInfo) Name() string       { return "file" }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return model.RegularFilePerm }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return false }
+func (i fileInfo) Sys() any           { return nil }
+
+// versionedStorage opens the readers of a file, its modification date changing on each open when changing, or
+// readers resuming themselves when resumable.
+type versionedStorage struct {
+	flakyStorage
+	opens     *atomic.Int32
+	resumes   *atomic.Int32
+	changing  bool
+	resumable bool
+}
+
+func (v versionedStorage) ReadFrom(_ context.Context, _ string) (model.ReadAtSeekCloser, error) {
+	opens := v.opens.Add(1)
+
+	reader := &flakyReader{Reader: strings.NewReader(v.content), storage: v.flakyStorage}
+
+	if v.resumable {
+		return resumingReader{flakyReader: reader, storage: v}, nil
+	}
+
+	modTime := time.Unix(0, 0)
+	if v.changing {
+		modTime = modTime.Add(time.Duration(opens) * time.Second)
+	}
+
+	return fileReader{flakyReader: reader, info: fileInfo{modTime: modTime, size: int64(len(v.content))}}, nil
+}
+
+type fileReader struct {
+	*flakyReader
+	info fileInfo
+}
+
+func (r fileReader) Stat() (fs.FileInfo, error) {
+	return r.info, nil
+}
+
+type resumingReader struct {
+	*flakyReader
+	storage versionedStorage
+}
+
+func (r resumingReader) Resume(_ context.Context) (model.ReadAtSeekCloser, error) {
+	r.storage.resumes.Add(1)
+
+	return resumingReader{flakyReader: &flakyReader{Reader: strings.NewReader(r.storage.content), storage: r.storage.flakyStorage}, storage: r.storage}, nil
+}
+
+func TestReadFromVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		changing    bool
+		resumable   bool
+		want        string
+		wantOpens   int32
+		wantResumes int32
+		wantErr     error
+	}{
+		"same file": {
+			false,
+			false,
+			"hello world",
+			2,
+			0,
+			nil,
+		},
+		"changed file": {
+			true,
+			false,
+			"hell",
+			2,
+			0,
+			model.ErrConflict,
+		},
+		"resumable": {
+			false,
+			true,
+			"hello world",
+			1,
+			1,
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			storage := versionedStorage{
+				flakyStorage: newFlakyStorage(1),
+				opens:        &atomic.Int32{},
+				resumes:      &atomic.Int32{},
+				changing:     tc.changing,
+				resumable:    tc.resumable,
+			}
+
+			reader, err := newTestService(storage).ReadFrom(context.Background(), "/file")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer func() { _ = reader.Close() }()
+
+			content, err := io.ReadAll(reader)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("ReadFrom() = `%v`, want `%v`", err, tc.wantErr)
+			}
+
+			if string(content) != tc.want {
+				t.Errorf("ReadFrom() = `%s`, want `%s`", content, tc.want)
+			}
+
+			if opens, resumes := storage.opens.Load(), storage.resumes.Load(); opens != tc.wantOpens || resumes != tc.wantResumes {
+				t.Errorf("ReadFrom() = %d opens and %d resumes, want %d and %d", opens, resumes, tc.wantOpens, tc.wantResumes)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+// DirectoryMode defines how the directories are represented in the bucket, which has no directory.
+type DirectoryMode uint8
+
+const (
+	// DirectoryMarkers writes an empty `name/` object for each directory, an empty directory being kept.
+	DirectoryMarkers DirectoryMode = iota
+	// DirectoryImplicit never writes a marker, a directory existing as long as it has content.
+	DirectoryImplicit
+)
+
+func ParseDirectoryMode(value string) (DirectoryMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "markers":
+		return DirectoryMarkers, nil
+	case "implicit":
+		return DirectoryImplicit, nil
+	default:
+		return DirectoryMarkers, fmt.Errorf("unknown directory mode `%s`", value)
+	}
+}
+
+func (a Service) Mkdir(ctx context.Context, name string, _ os.FileMode) error {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	if a.directoryMode == DirectoryImplicit {
+		return nil
+	}
+
+	// The markers are checked from the deepest one, the existing ones being never rewritten
+	var missing []string
+
+	for key := strings.Trim(a.Path(name), "/"); len(key) != 0 && key != "."; key = path.Dir(key) {
+		dirname := model.Dirname(key)
+
+		if _, err = a.client.StatObject(ctx, a.bucket, dirname, minio.GetObjectOptions{}); err == nil {
+			break
+		}
+
+		if !IsNotExist(err) {
+			return a.pathError("stat directory", dirname, err)
+		}
+
+		missing = append(missing, dirname)
+	}
+
+	for _, dirname := range slices.Backward(missing) {
+		if _, err = a.client.PutObject(ctx, a.bucket, dirname, strings.NewReader(""), 0, minio.PutObjectOptions{
+			StorageClass: a.storageClass,
+		}); err != nil {
+			return a.pathError("create directory", dirname, err)
+		}
+	}
+
+	return nil
+}
+
+// statDirectory returns the directory of the given key ending with a slash, existing when it has a marker or a content.
+func (a Service) statDirectory(ctx context.Context, key string) (model.Item, error) {
+	if a.directoryMode == DirectoryMarkers {
+		info, err := a.client.StatObject(ctx, a.bucket, key, minio.GetObjectOptions{})
+		if err == nil {
+			return convertToItem(info), nil
+		}
+
+		if !IsNotExist(err) {
+			return model.Item{}, a.pathError("stat object", key, err)
+		}
+	}
+
+	for _, err := range a.listObjects(ctx, minio.ListObjectsOptions{Prefix: key, MaxKeys: 1}) {
+		if err != nil {
+			return model.Item{}, err
+		}
+
+		return convertToItem(minio.ObjectInfo{Key: key}), nil
+	}
+
+	return model.Item{}, model.ErrNotExist(fmt.Errorf("directory `/%s`", key))
+}
+
+// namedObjects lists the objects of the key, the file of the exact key then the content of the `key/` directory with its
+// marker, the keys only sharing its prefix being never listed.
+func (a Service) namedObjects(ctx context.Context, key string, withMetadata bool) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		if len(key) != 0 && !strings.HasSuffix(key, "/") {
+			info, err := a.client.StatObject(ctx, a.bucket, key, minio.GetObjectOptions{})
+			if err == nil {
+				if !yield(info, nil) {
+					return
+				}
+			} else if !IsNotExist(err) {
+				yield(minio.ObjectInfo{}, a.pathError("stat object", key, err))
+				return
+			}
+		}
+
+		for object, err := range a.listObjects(ctx, minio.ListObjectsOptions{
+			Prefix:       dirPrefix(key),
+			Recursive:    true,
+			WithMetadata: withMetadata,
+		}) {
+			if !yield(object, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// withDirectories adds the directories implied by the keys under the prefix in implicit mode, before their first
+// content, a marker left from the markers mode being returned once.
+func (a Service) withDirectories(prefix string, objects iter.Seq2[minio.ObjectInfo, error]) iter.Seq2[minio.ObjectInfo, error] {
+	if a.directoryMode != DirectoryImplicit {
+		return objects
+	}
+
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		seen := make(map[string]struct{})
+
+		for object, err := range objects {
+			if err != nil {
+				yield(object, err)
+				return
+			}
+
+			for index := max(len(prefix)-1, 0); index < len(object.Key); index++ {
+				if object.Key[index] != '/' {
+					continue
+				}
+
+				dirname := object.Key[:index+1]
+				if _, ok := seen[dirname]; ok {
+					continue
+				}
+
+				seen[dirname] = struct{}{}
+
+				if !yield(minio.ObjectInfo{Key: dirname}, nil) {
+					return
+				}
+			}
+
+			if !strings.HasSuffix(object.Key, "/") && !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package s3
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestParseDirectoryMode(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		value   string
+		want    DirectoryMode
+		wantErr bool
+	}{
+		"default": {
+			"",
+			DirectoryMarkers,
+			false,
+		},
+		"implicit": {
+			" Implicit ",
+			DirectoryImplicit,
+			false,
+		},
+		"unknown": {
+			"folders",
+			DirectoryMarkers,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := ParseDirectoryMode(tc.value)
+
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("ParseDirectoryMode() error = `%v`, want error %t", gotErr, tc.wantErr)
+			}
+
+			if got != tc.want {
+				t.Errorf("ParseDirectoryMode() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithDirectories(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"photos/2024/a.jpg", "photos/2024/b.jpg", "photos/2025/", "photos/2025/c.jpg", "photos/cover.jpg"}
+
+	cases := map[string]struct {
+		directoryMode DirectoryMode
+		prefix        string
+		want          []string
+	}{
+		"markers": {
+			DirectoryMarkers,
+			"photos/",
+			keys,
+		},
+		"implicit": {
+			DirectoryImplicit,
+			"photos/",
+			[]string{"photos/", "photos/2024/", "photos/2024/a.jpg", "photos/2024/b.jpg", "photos/2025/", "photos/2025/c.jpg", "photos/cover.jpg"},
+		},
+		"implicit root": {
+			DirectoryImplicit,
+			"",
+			[]string{"photos/", "photos/2024/", "photos/2024/a.jpg", "photos/2024/b.jpg", "photos/2025/", "photos/2025/c.jpg", "photos/cover.jpg"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			objects := func(yield func(minio.ObjectInfo, error) bool) {
+				for _, key := range keys {
+					if !yield(minio.ObjectInfo{Key: key}, nil) {
+						return
+					}
+				}
+			}
+
+			var got []string
+
+			for object, err := range (Service{directoryMode: tc.directoryMode}).withDirectories(tc.prefix, objects) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got = append(got, object.Key)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("withDirectories() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
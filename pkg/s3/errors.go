@@ -0,0 +1,133 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+// ErrSizeRequired is returned by a conditional write of a large content without its size, it can't be sent in a
+// single request.
+var ErrSizeRequired = errors.New("conditional write requires the content size")
+
+// Error carries the details of a failed request, for correlating with the server logs.
+type Error struct {
+	Err        error
+	Code       string
+	RequestID  string
+	StatusCode int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (status: %d, code: %s, request: %s)", e.Err, e.StatusCode, e.Code, e.RequestID)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func IsNotExist(err error) bool {
+	response, ok := toErrorResponse(err)
+
+	return ok && isNotExistResponse(response)
+}
+
+// ConvertError maps the error to the taxonomy, a failed precondition being a conflict with another writer.
+func (a Service) ConvertError(err error) error {
+	return a.convertError(err, model.ErrConflict)
+}
+
+// convertCreateError converts the error of a request made with If-None-Match, its failed precondition meaning that the
+// object already exists.
+func (a Service) convertCreateError(err error) error {
+	return a.convertError(err, model.ErrExist)
+}
+
+func (a Service) convertError(err, preconditionErr error) error {
+	if err == nil {
+		return nil
+	}
+
+	var s3Err *Error
+	if errors.As(err, &s3Err) {
+		return err
+	}
+
+	response, ok := toErrorResponse(err)
+	if !ok {
+		var netErr net.Error
+
+		// A context deadline is also a net.Error, it is not an unavailability of the storage
+		if errors.As(err, &netErr) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+			return model.WrapError(err, model.ErrUnavailable)
+		}
+
+		return err
+	}
+
+	s3Err = &Error{
+		Err:        err,
+		Code:       response.Code,
+		RequestID:  response.RequestID,
+		StatusCode: response.StatusCode,
+	}
+
+	switch {
+	case isNotExistResponse(response):
+		return model.ErrNotExist(s3Err)
+	case isUnavailableResponse(response):
+		return model.WrapError(s3Err, model.ErrUnavailable)
+	case response.Code == "AccessDenied", response.StatusCode == http.StatusForbidden:
+		return model.WrapError(s3Err, model.ErrPermission)
+	case response.Code == minio.PreconditionFailed, response.StatusCode == http.StatusPreconditionFailed:
+		return model.WrapError(s3Err, preconditionErr)
+	case response.Code == "InvalidRange", response.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return model.WrapError(s3Err, io.EOF)
+	case response.Code == "BucketNotEmpty":
+		return model.WrapError(s3Err, model.ErrNotEmpty)
+	case response.Code == "EntityTooLarge", response.Code == "QuotaExceeded", response.Code == "XMinioStorageFull":
+		return model.WrapError(s3Err, model.ErrQuotaExceeded)
+	default:
+		return s3Err
+	}
+}
+
+// pathError converts the error of the operation on the named object, recording them as the filesystem does.
+func (a Service) pathError(op, name string, err error) error {
+	return &model.PathError{Op: op, Path: "/" + strings.TrimPrefix(name, "/"), Backend: Name, Err: a.ConvertError(err)}
+}
+
+// toErrorResponse finds the response in the chain, minio.ToErrorResponse only handling an unwrapped error.
+func toErrorResponse(err error) (minio.ErrorResponse, bool) {
+	var response minio.ErrorResponse
+
+	return response, errors.As(err, &response)
+}
+
+func isNotExistResponse(response minio.ErrorResponse) bool {
+	switch response.Code {
+	case minio.NoSuchKey, minio.NoSuchBucket, "NoSuchUpload", "NoSuchVersion":
+		return true
+	default:
+		return response.StatusCode == http.StatusNotFound
+	}
+}
+
+// isUnavailableResponse reports whether the request may succeed once retried: a server error, a throttling or a
+// request too slow or too skewed to be accepted. The codes are checked first, some being sent with a client status.
+func isUnavailableResponse(response minio.ErrorResponse) bool {
+	switch response.Code {
+	case "SlowDown", "ServiceUnavailable", "InternalError", "Throttling", "ThrottlingException", "TooManyRequests",
+		"RequestLimitExceeded", "RequestThrottled", "RequestTimeout", "RequestTimeTooSkewed", "XMinioServerNotInitialized":
+		return true
+	default:
+		return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+	}
+}
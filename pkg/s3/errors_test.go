@@ -0,0 +1,232 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestConvertError(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		args         args
+		want         error
+		wantNotExist bool
+		wantS3       bool
+	}{
+		"nil": {
+			args{
+				err: nil,
+			},
+			nil,
+			false,
+			false,
+		},
+		"no such key": {
+			args{
+				err: fmt.Errorf("get object: %w", minio.ErrorResponse{Code: minio.NoSuchKey, StatusCode: http.StatusNotFound, RequestID: "req-1"}),
+			},
+			nil,
+			true,
+			true,
+		},
+		"no such bucket": {
+			args{
+				err: minio.ErrorResponse{Code: minio.NoSuchBucket, StatusCode: http.StatusNotFound, RequestID: "req-1"},
+			},
+			nil,
+			true,
+			true,
+		},
+		"not found without code": {
+			args{
+				err: minio.ErrorResponse{StatusCode: http.StatusNotFound, RequestID: "req-1"},
+			},
+			nil,
+			true,
+			true,
+		},
+		"access denied": {
+			args{
+				err: fmt.Errorf("put object: %w", minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden, RequestID: "req-1"}),
+			},
+			model.ErrPermission,
+			false,
+			true,
+		},
+		"slow down": {
+			args{
+				err: minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable, RequestID: "req-1"},
+			},
+			model.ErrUnavailable,
+			false,
+			true,
+		},
+		"bad gateway": {
+			args{
+				err: minio.ErrorResponse{StatusCode: http.StatusBadGateway, RequestID: "req-1"},
+			},
+			model.ErrUnavailable,
+			false,
+			true,
+		},
+		"gateway timeout": {
+			args{
+				err: minio.ErrorResponse{StatusCode: http.StatusGatewayTimeout, RequestID: "req-1"},
+			},
+			model.ErrUnavailable,
+			false,
+			true,
+		},
+		"too many requests": {
+			args{
+				err: minio.ErrorResponse{Code: "TooManyRequests", StatusCode: http.StatusTooManyRequests, RequestID: "req-1"},
+			},
+			model.ErrUnavailable,
+			false,
+			true,
+		},
+		"request time too skewed": {
+			args{
+				err: minio.ErrorResponse{Code: "RequestTimeTooSkewed", StatusCode: http.StatusForbidden, RequestID: "req-1"},
+			},
+			model.ErrUnavailable,
+			false,
+			true,
+		},
+		"precondition failed": {
+			args{
+				err: minio.ErrorResponse{Code: minio.PreconditionFailed, StatusCode: http.StatusPreconditionFailed, RequestID: "req-1"},
+			},
+			model.ErrConflict,
+			false,
+			true,
+		},
+		"invalid range": {
+			args{
+				err: minio.ErrorResponse{Code: "InvalidRange", StatusCode: http.StatusRequestedRangeNotSatisfiable, RequestID: "req-1"},
+			},
+			io.EOF,
+			false,
+			true,
+		},
+		"unknown code": {
+			args{
+				err: minio.ErrorResponse{Code: "MalformedXML", StatusCode: http.StatusBadRequest, RequestID: "req-1"},
+			},
+			nil,
+			false,
+			true,
+		},
+		"network": {
+			args{
+				err: fmt.Errorf("list objects: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}),
+			},
+			model.ErrUnavailable,
+			false,
+			false,
+		},
+		"deadline": {
+			args{
+				err: fmt.Errorf("list objects: %w", context.DeadlineExceeded),
+			},
+			context.DeadlineExceeded,
+			false,
+			false,
+		},
+		"standard": {
+			args{
+				err: errors.New("read"),
+			},
+			nil,
+			false,
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got := Service{}.ConvertError(tc.args.err)
+
+			if tc.args.err == nil {
+				if got != nil {
+					t.Errorf("ConvertError() = `%v`, want nil", got)
+				}
+
+				return
+			}
+
+			if tc.want != nil && !errors.Is(got, tc.want) {
+				t.Errorf("ConvertError() = `%v`, want `%v`", got, tc.want)
+			}
+
+			if model.IsNotExist(got) != tc.wantNotExist {
+				t.Errorf("ConvertError() = `%v`, want not exist %t", got, tc.wantNotExist)
+			}
+
+			var s3Err *Error
+			if errors.As(got, &s3Err) != tc.wantS3 {
+				t.Fatalf("ConvertError() = `%v`, want S3 error %t", got, tc.wantS3)
+			}
+
+			if tc.wantS3 && (s3Err.RequestID != "req-1" || s3Err.StatusCode == 0 || !strings.Contains(got.Error(), "req-1")) {
+				t.Errorf("ConvertError() = `%v`, want the request ID and the status", got)
+			}
+		})
+	}
+}
+
+func TestPathError(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err  error
+		want error
+	}{
+		"access denied": {
+			minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden, RequestID: "req-1"},
+			model.ErrPermission,
+		},
+		"slow down": {
+			minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable, RequestID: "req-1"},
+			model.ErrUnavailable,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got := Service{}.pathError("put object", "dir/file.txt", tc.err)
+
+			var pathErr *model.PathError
+			if !errors.As(got, &pathErr) || pathErr.Op != "put object" || pathErr.Path != "/dir/file.txt" || pathErr.Backend != Name {
+				t.Errorf("pathError() = `%v`, want a path error", got)
+			}
+
+			if !errors.Is(got, tc.want) {
+				t.Errorf("pathError() = `%v`, want `%v`", got, tc.want)
+			}
+
+			var s3Err *Error
+			if !errors.As(got, &s3Err) || s3Err.RequestID != "req-1" {
+				t.Errorf("pathError() = `%v`, want the S3 error", got)
+			}
+		})
+	}
+}
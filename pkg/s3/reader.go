@@ -0,0 +1,44 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+var _ model.ResumableReader = object{}
+
+// object reads an object, a resumed read being conditioned on the ETag of the first one.
+type object struct {
+	*minio.Object
+	service Service
+	key     string
+}
+
+func (o object) Resume(ctx context.Context) (model.ReadAtSeekCloser, error) {
+	info, err := o.Stat()
+	if err != nil {
+		return nil, o.service.pathError("stat object", o.key, err)
+	}
+
+	opts := minio.GetObjectOptions{}
+	if err = opts.SetMatchETag(info.ETag); err != nil {
+		return nil, fmt.Errorf("match etag: %w", err)
+	}
+
+	reader, err := o.service.client.GetObject(ctx, o.service.bucket, o.key, opts)
+	if err != nil {
+		return nil, o.service.pathError("get object", o.key, err)
+	}
+
+	// The object is requested lazily, its ETag being checked before resuming
+	if _, err = reader.Stat(); err != nil {
+		_ = reader.Close()
+
+		return nil, o.service.pathError("get object", o.key, err)
+	}
+
+	return object{Object: reader, service: o.service, key: o.key}, nil
+}
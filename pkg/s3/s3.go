@@ -1,11 +1,16 @@
 package s3
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"iter"
+	"net/http"
+	"net/url"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,13 +19,28 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
-const Name = "object"
+const (
+	Name = "object"
+
+	removeBatchSize = 1000
+	minComposeSize  = 5 << 20
+	maxCopyPartSize = 5 << 30
+	maxObjectSize   = 5 << 40
+
+	// defaultBufferSize bounds the content of unknown size read in memory when no part size is configured.
+	defaultBufferSize = 16 << 20
+)
 
 var _ model.Storage = Service{}
 
 type Config struct {
-	region       string
-	storageClass string
+	region        string
+	storageClass  string
+	tokenSecret   string
+	pathPolicy    model.PathPolicy
+	pollInterval  time.Duration
+	directoryMode DirectoryMode
+	versioning    bool
 }
 
 type ConfigOption func(Config) Config
@@ -41,12 +61,61 @@ func WithStorageClass(storageClass string) ConfigOption {
 	}
 }
 
+func WithPollInterval(pollInterval time.Duration) ConfigOption {
+	return func(instance Config) Config {
+		instance.pollInterval = pollInterval
+
+		return instance
+	}
+}
+
+// WithPathPolicy sets the validation and normalization of the names, model.DefaultPathPolicy by default.
+func WithPathPolicy(pathPolicy model.PathPolicy) ConfigOption {
+	return func(instance Config) Config {
+		instance.pathPolicy = pathPolicy
+
+		return instance
+	}
+}
+
+// WithDirectoryMode sets the representation of the directories, DirectoryMarkers by default.
+func WithDirectoryMode(directoryMode DirectoryMode) ConfigOption {
+	return func(instance Config) Config {
+		instance.directoryMode = directoryMode
+
+		return instance
+	}
+}
+
+// WithVersioning declares that the bucket has versioning enabled.
+// WithTokenSecret sets the secret signing the continuation tokens, a random one for the process by default.
+func WithTokenSecret(secret string) ConfigOption {
+	return func(instance Config) Config {
+		instance.tokenSecret = secret
+
+		return instance
+	}
+}
+
+func WithVersioning() ConfigOption {
+	return func(instance Config) Config {
+		instance.versioning = true
+
+		return instance
+	}
+}
+
 type Service struct {
-	client       *minio.Client
-	ignoreFn     func(model.Item) bool
-	bucket       string
-	storageClass string
-	partSize     uint64
+	client        *minio.Client
+	ignoreFn      func(model.Item) bool
+	bucket        string
+	storageClass  string
+	tokenKey      []byte
+	pathPolicy    model.PathPolicy
+	partSize      uint64
+	pollInterval  time.Duration
+	directoryMode DirectoryMode
+	versioning    bool
 }
 
 func New(endpoint, accessKey, secretAccess, bucket string, useSSL bool, partSize uint64, options ...ConfigOption) (Service, error) {
@@ -54,7 +123,11 @@ func New(endpoint, accessKey, secretAccess, bucket string, useSSL bool, partSize
 		return Service{}, nil
 	}
 
-	var config Config
+	config := Config{
+		pathPolicy:   model.DefaultPathPolicy,
+		pollInterval: model.DefaultPollInterval,
+	}
+
 	for _, option := range options {
 		config = option(config)
 	}
@@ -69,10 +142,15 @@ func New(endpoint, accessKey, secretAccess, bucket string, useSSL bool, partSize
 	}
 
 	return Service{
-		client:       client,
-		bucket:       bucket,
-		storageClass: config.storageClass,
-		partSize:     partSize,
+		client:        client,
+		bucket:        bucket,
+		storageClass:  config.storageClass,
+		tokenKey:      model.TokenKey(config.tokenSecret),
+		pathPolicy:    config.pathPolicy,
+		partSize:      partSize,
+		pollInterval:  config.pollInterval,
+		directoryMode: config.directoryMode,
+		versioning:    config.versioning,
 	}, nil
 }
 
@@ -80,6 +158,22 @@ func (a Service) Enabled() bool {
 	return a.client != nil
 }
 
+// Close does nothing, the client holding no resource to release.
+func (a Service) Close() error {
+	return nil
+}
+
+// Capabilities reports a non atomic rename and no date update because objects are immutable, a rename being a copy then a deletion.
+func (a Service) Capabilities() model.Capabilities {
+	return model.Capabilities{
+		MaxObjectSize:  maxObjectSize,
+		RangeReads:     true,
+		ServerSideCopy: true,
+		Versioning:     a.versioning,
+		SignedURL:      true,
+	}
+}
+
 func (a Service) Name() string {
 	return Name
 }
@@ -95,7 +189,8 @@ func (a Service) Path(pathname string) string {
 }
 
 func (a Service) Stat(ctx context.Context, pathname string) (model.Item, error) {
-	if err := model.ValidPath(pathname); err != nil {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
 		return model.Item{}, err
 	}
 
@@ -110,63 +205,170 @@ func (a Service) Stat(ctx context.Context, pathname string) (model.Item, error)
 		}, nil
 	}
 
+	if strings.HasSuffix(realPathname, "/") {
+		return a.statDirectory(ctx, realPathname)
+	}
+
 	info, err := a.client.StatObject(ctx, a.bucket, realPathname, minio.GetObjectOptions{})
-	if err != nil {
-		if strings.HasSuffix(realPathname, "/") && IsNotExist(err) && a.dirExists(ctx, realPathname) {
-			return convertToItem(minio.ObjectInfo{Key: realPathname}), nil
+	if err == nil {
+		return convertToItem(info), nil
+	}
+
+	if IsNotExist(err) {
+		// A name without its trailing slash is also a directory
+		return a.statDirectory(ctx, dirPrefix(realPathname))
+	}
+
+	return model.Item{}, a.pathError("stat object", pathname, err)
+}
+
+func (a Service) List(ctx context.Context, pathname string) ([]model.Item, error) {
+	var items []model.Item
+
+	for item, err := range a.ListSeq(ctx, pathname) {
+		if err != nil {
+			return nil, err
 		}
 
-		return model.Item{}, a.ConvertError(fmt.Errorf("stat object `%s`: %w", pathname, err))
+		items = append(items, item)
 	}
 
-	return convertToItem(info), nil
+	return items, nil
 }
 
-func (a Service) dirExists(ctx context.Context, realPathname string) bool {
-	objectsCh := a.client.ListObjects(ctx, a.bucket, minio.ListObjectsOptions{
-		Prefix:  realPathname,
-		MaxKeys: 1,
-	})
+func (a Service) ListPage(ctx context.Context, pathname string, opts model.ListOpts) (model.Page, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	prefix := "/" + a.Path(pathname)
 
-	var found uint
-	for range objectsCh {
-		found++
+	cursor, err := opts.Cursor(a.tokenKey, prefix)
+	if err != nil {
+		return model.Page{}, err
 	}
 
-	return found > 0
+	pageSize := opts.PageSize()
+
+	return model.CollectPage(a.listItems(ctx, pathname, minio.ListObjectsOptions{
+		Prefix:       a.Path(pathname),
+		StartAfter:   a.Path(cursor),
+		MaxKeys:      min(pageSize+1, model.DefaultListLimit),
+		WithMetadata: true,
+	}), a.tokenKey, prefix, cursor, pageSize)
 }
 
-func (a Service) List(ctx context.Context, pathname string) ([]model.Item, error) {
-	if err := model.ValidPath(pathname); err != nil {
+func (a Service) Glob(ctx context.Context, pattern string) ([]model.Item, error) {
+	matcher, err := model.NewPattern(pattern, a.pathPolicy)
+	if err != nil {
 		return nil, err
 	}
 
-	realPathname := a.Path(pathname)
-	baseRealPathname := path.Base(realPathname)
+	var items []model.Item
 
-	objectsCh := a.client.ListObjects(ctx, a.bucket, minio.ListObjectsOptions{
-		Prefix: realPathname,
-	})
+	// The literal prefix of the pattern is not a name, every key starting with it being a candidate
+	prefix := a.Path(matcher.Prefix())
 
-	var items []model.Item
-	for object := range objectsCh {
-		item := convertToItem(object)
-		if item.IsDir() && item.Name() == baseRealPathname {
-			continue
+	for item, err := range a.walkItems(prefix, model.WalkOpts{}, a.listObjects(ctx, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithMetadata: true,
+	})) {
+		if err != nil {
+			return nil, err
 		}
 
-		if a.ignoreFn != nil && a.ignoreFn(item) {
-			continue
+		if matcher.Match(item.Pathname) {
+			items = append(items, item)
 		}
-
-		items = append(items, item)
 	}
 
 	return items, nil
 }
 
+func (a Service) ListSeq(ctx context.Context, pathname string) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		pathname, err := a.cleanPath(pathname)
+		if err != nil {
+			yield(model.Item{}, err)
+			return
+		}
+
+		for item, err := range a.listItems(ctx, pathname, minio.ListObjectsOptions{
+			Prefix:       a.Path(pathname),
+			WithMetadata: true,
+		}) {
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+func (a Service) listItems(ctx context.Context, pathname string, opts minio.ListObjectsOptions) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		baseRealPathname := path.Base(a.Path(pathname))
+
+		for object, err := range a.listObjects(ctx, opts) {
+			if err != nil {
+				yield(model.Item{}, err)
+				return
+			}
+
+			if isAppendPart(object.Key) {
+				continue
+			}
+
+			item := convertToItem(object)
+			if item.IsDir() && item.Name() == baseRealPathname {
+				continue
+			}
+
+			if a.ignoreFn != nil && a.ignoreFn(item) {
+				continue
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (a Service) listObjects(ctx context.Context, opts minio.ListObjectsOptions) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+
+		objectsCh := a.client.ListObjects(ctx, a.bucket, opts)
+
+		defer func() {
+			cancel()
+
+			for range objectsCh {
+			}
+		}()
+
+		for object := range objectsCh {
+			if object.Err != nil {
+				yield(object, a.ConvertError(fmt.Errorf("list objects: %w", object.Err)))
+				return
+			}
+
+			if !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (a Service) WriteTo(ctx context.Context, pathname string, reader io.Reader, opts model.WriteOpts) error {
-	if err := model.ValidPath(pathname); err != nil {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	if err := model.ValidTags(opts.Tags); err != nil {
 		return err
 	}
 
@@ -174,182 +376,480 @@ func (a Service) WriteTo(ctx context.Context, pathname string, reader io.Reader,
 		opts.Size = -1
 	}
 
-	if _, err := a.client.PutObject(ctx, a.bucket, a.Path(pathname), reader, opts.Size, minio.PutObjectOptions{
-		PartSize:     a.partSize,
-		StorageClass: a.storageClass,
-	}); err != nil {
-		return fmt.Errorf("put object: %w", err)
-	}
+	key := a.Path(pathname)
+	putOpts := a.putOptions(opts)
 
-	return nil
+	switch opts.Mode {
+	case model.Append:
+		return a.appendObject(ctx, key, reader, opts.Size, putOpts)
+
+	case model.CreateExclusive:
+		reader, size, err := a.sizedContent(reader, opts.Size)
+		if err != nil {
+			return a.pathError("put object", pathname, err)
+		}
+
+		putOpts.SetMatchETagExcept("*")
+		putOpts.DisableMultipart = true
+
+		if _, err := a.client.PutObject(ctx, a.bucket, key, reader, size, putOpts); err != nil {
+			return a.pathError("put object", pathname, a.convertCreateError(err))
+		}
+
+		return nil
+
+	default:
+		if _, err := a.client.PutObject(ctx, a.bucket, key, reader, opts.Size, putOpts); err != nil {
+			return a.pathError("put object", pathname, err)
+		}
+
+		return nil
+	}
 }
 
-func (a Service) ReadFrom(ctx context.Context, pathname string) (model.ReadAtSeekCloser, error) {
-	if err := model.ValidPath(pathname); err != nil {
-		return nil, err
+// sizedContent returns the content of a conditional write with its size. The precondition being unreliable on a
+// multipart upload, a content of unknown size is read in memory, up to the part size, to be sent in a single PUT.
+func (a Service) sizedContent(reader io.Reader, size int64) (io.Reader, int64, error) {
+	if size >= 0 {
+		return reader, size, nil
 	}
 
-	object, err := a.client.GetObject(ctx, a.bucket, a.Path(pathname), minio.GetObjectOptions{})
+	limit := int64(a.partSize)
+	if limit == 0 {
+		limit = defaultBufferSize
+	}
+
+	content, err := io.ReadAll(io.LimitReader(reader, limit+1))
 	if err != nil {
-		return nil, a.ConvertError(fmt.Errorf("get object `%s`: %w", pathname, err))
+		return nil, 0, fmt.Errorf("read content: %w", err)
 	}
 
-	return object, nil
+	if int64(len(content)) > limit {
+		return nil, 0, fmt.Errorf("content larger than %d bytes: %w", limit, ErrSizeRequired)
+	}
+
+	return bytes.NewReader(content), int64(len(content)), nil
 }
 
-func (a Service) UpdateDate(_ context.Context, _ string, _ time.Time) error {
+func (a Service) putOptions(opts model.WriteOpts) minio.PutObjectOptions {
+	return minio.PutObjectOptions{
+		PartSize:        a.partSize,
+		StorageClass:    a.storageClass,
+		ContentType:     opts.ContentType,
+		ContentEncoding: opts.ContentEncoding,
+		CacheControl:    opts.CacheControl,
+		UserMetadata:    opts.Metadata,
+		UserTags:        opts.Tags,
+	}
+}
+
+// appendObject emulates an append by composing the existing object with the new content, or by rewriting it
+// when the existing object is too small to be used as a multipart copy source.
+func (a Service) appendObject(ctx context.Context, key string, reader io.Reader, size int64, putOpts minio.PutObjectOptions) (err error) {
+	info, err := a.client.StatObject(ctx, a.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if !IsNotExist(err) {
+			return a.pathError("stat object", key, err)
+		}
+
+		if _, err = a.client.PutObject(ctx, a.bucket, key, reader, size, putOpts); err != nil {
+			return a.pathError("put object", key, err)
+		}
+
+		return nil
+	}
+
+	if len(putOpts.UserMetadata) == 0 {
+		putOpts.UserMetadata = info.UserMetadata
+	}
+
+	if len(putOpts.ContentType) == 0 {
+		putOpts.ContentType = info.ContentType
+	}
+
+	if len(putOpts.ContentEncoding) == 0 {
+		putOpts.ContentEncoding = info.ContentEncoding
+	}
+
+	if len(putOpts.CacheControl) == 0 {
+		putOpts.CacheControl = info.Metadata.Get("Cache-Control")
+	}
+
+	// Tags are not returned when stating an object, they have to be fetched to be kept
+	if len(putOpts.UserTags) == 0 && info.UserTagCount != 0 {
+		objectTags, err := a.client.GetObjectTagging(ctx, a.bucket, key, minio.GetObjectTaggingOptions{})
+		if err != nil {
+			return a.pathError("get object tagging", key, err)
+		}
+
+		putOpts.UserTags = objectTags.ToMap()
+	}
+
+	if info.Size < minComposeSize {
+		return a.rewriteObject(ctx, key, info, reader, size, putOpts)
+	}
+
+	appendKey := appendPartKey(key, time.Now())
+
+	if _, err = a.client.PutObject(ctx, a.bucket, appendKey, reader, size, putOpts); err != nil {
+		return a.pathError("put appended part", appendKey, err)
+	}
+
+	defer func() {
+		if removeErr := a.client.RemoveObject(context.WithoutCancel(ctx), a.bucket, appendKey, minio.RemoveObjectOptions{}); removeErr != nil {
+			err = errors.Join(err, fmt.Errorf("delete appended part: %w", removeErr))
+		}
+	}()
+
+	if err = a.composeObject(ctx, key, info, appendKey, putOpts); err != nil {
+		return a.pathError("compose object", key, err)
+	}
+
 	return nil
 }
 
-func (a Service) Walk(ctx context.Context, pathname string, walkFn func(model.Item) error) error {
-	if err := model.ValidPath(pathname); err != nil {
+// composeObject writes the object followed by the appended part with a multipart copy. The object is only replaced if
+// no other writer changed it since it was read, a concurrent append being a conflict.
+func (a Service) composeObject(ctx context.Context, key string, info minio.ObjectInfo, appendKey string, putOpts minio.PutObjectOptions) (err error) {
+	core := a.core()
+
+	uploadID, err := core.NewMultipartUpload(ctx, a.bucket, key, putOpts)
+	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, core.AbortMultipartUpload(context.WithoutCancel(ctx), a.bucket, key, uploadID))
+		}
+	}()
 
-	objectsCh := a.client.ListObjects(ctx, a.bucket, minio.ListObjectsOptions{
-		Prefix:    a.Path(pathname),
-		Recursive: true,
-	})
+	sourceMatch := map[string]string{"x-amz-copy-source-if-match": info.ETag}
 
-	var err error
-	var ignoredPrefixes []string
+	var parts []minio.CompletePart
 
-	for object := range objectsCh {
+	for offset := int64(0); offset < info.Size; offset += maxCopyPartSize {
+		part, err := core.CopyObjectPart(ctx, a.bucket, key, a.bucket, key, uploadID, len(parts)+1, offset, min(maxCopyPartSize, info.Size-offset), sourceMatch)
 		if err != nil {
-			continue
+			return err
 		}
 
-		item := convertToItem(object)
+		parts = append(parts, part)
+	}
 
-		if a.ignoreFn != nil {
-			var ignored bool
+	part, err := core.CopyObjectPart(ctx, a.bucket, appendKey, a.bucket, key, uploadID, len(parts)+1, 0, -1, nil)
+	if err != nil {
+		return err
+	}
 
-			for _, prefix := range ignoredPrefixes {
-				if strings.HasPrefix(item.Pathname, prefix) {
-					ignored = true
+	completeOpts := minio.PutObjectOptions{}
+	completeOpts.SetMatchETag(info.ETag)
 
-					break
-				}
-			}
+	_, err = core.CompleteMultipartUpload(ctx, a.bucket, key, uploadID, append(parts, part), completeOpts)
 
-			if ignored {
-				continue
-			}
+	return err
+}
 
-			if a.ignoreFn(item) {
-				if item.IsDir() {
-					ignoredPrefixes = append(ignoredPrefixes, item.Pathname)
-				}
+func (a Service) rewriteObject(ctx context.Context, key string, info minio.ObjectInfo, reader io.Reader, size int64, putOpts minio.PutObjectOptions) error {
+	getOpts := minio.GetObjectOptions{}
+	if err := getOpts.SetMatchETag(info.ETag); err != nil {
+		return fmt.Errorf("match etag: %w", err)
+	}
 
-				continue
-			}
+	object, err := a.client.GetObject(ctx, a.bucket, key, getOpts)
+	if err != nil {
+		return a.pathError("get object", key, err)
+	}
+
+	defer func() { _ = object.Close() }()
+
+	reader, size, err = a.sizedContent(reader, size)
+	if err != nil {
+		return a.pathError("put object", key, err)
+	}
+
+	// The object is only replaced if no other writer changed it since it was read, a concurrent append being a conflict
+	putOpts.SetMatchETag(info.ETag)
+	putOpts.DisableMultipart = true
+
+	if _, err = a.client.PutObject(ctx, a.bucket, key, io.MultiReader(object, reader), info.Size+size, putOpts); err != nil {
+		return a.pathError("put object", key, err)
+	}
+
+	return nil
+}
+
+func (a Service) ReadFrom(ctx context.Context, pathname string) (model.ReadAtSeekCloser, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	key := a.Path(pathname)
+
+	reader, err := a.client.GetObject(ctx, a.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, a.pathError("get object", pathname, err)
+	}
+
+	return object{Object: reader, service: a, key: key}, nil
+}
+
+func (a Service) SignedURL(ctx context.Context, pathname, method string, expiry time.Duration) (string, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return "", err
+	}
+
+	var output *url.URL
+
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		output, err = a.client.PresignedGetObject(ctx, a.bucket, a.Path(pathname), expiry, nil)
+	case http.MethodHead:
+		output, err = a.client.PresignedHeadObject(ctx, a.bucket, a.Path(pathname), expiry, nil)
+	case http.MethodPut:
+		output, err = a.client.PresignedPutObject(ctx, a.bucket, a.Path(pathname), expiry)
+	default:
+		return "", fmt.Errorf("sign `%s` method: %w", method, errors.ErrUnsupported)
+	}
+
+	if err != nil {
+		return "", a.pathError("presign object", pathname, err)
+	}
+
+	return output.String(), nil
+}
+
+// SignedHandler returns a not found handler because presigned URLs are served by the object storage itself.
+func (a Service) SignedHandler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func (a Service) UpdateDate(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func (a Service) Walk(ctx context.Context, pathname string, walkFn func(model.Item) error) error {
+	for item, err := range a.All(ctx, pathname, model.WalkOpts{}) {
+		if err != nil {
+			return err
 		}
 
 		if err = walkFn(item); err != nil {
-			cancel()
+			return err
 		}
 	}
 
-	return err
+	return nil
 }
 
-func (a Service) Mkdir(ctx context.Context, name string, _ os.FileMode) error {
-	if err := model.ValidPath(name); err != nil {
-		return err
+func (a Service) All(ctx context.Context, pathname string, opts model.WalkOpts) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		pathname, err := a.cleanPath(pathname)
+		if err != nil {
+			yield(model.Item{}, err)
+			return
+		}
+
+		key := a.Path(pathname)
+
+		for item, err := range a.walkItems(dirPrefix(key), opts, a.namedObjects(ctx, key, true)) {
+			if !yield(item, err) {
+				return
+			}
+		}
 	}
+}
 
-	parts := strings.Split(model.Dirname(a.Path(name)), "/")
+// walkItems converts the objects listed under the prefix, with their directories, according to the walk options.
+func (a Service) walkItems(prefix string, opts model.WalkOpts, objects iter.Seq2[minio.ObjectInfo, error]) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		var ignoredPrefixes []string
 
-	for index := range parts {
-		dirname := strings.Join(parts[:index], "/")
+		for object, err := range a.withDirectories(prefix, objects) {
+			if err != nil {
+				yield(model.Item{}, err)
+				return
+			}
 
-		if _, err := a.Stat(ctx, dirname); err != nil {
-			if !model.IsNotExist(err) {
-				return fmt.Errorf("info `%s`: %w", dirname, err)
+			if isAppendPart(object.Key) || opts.MaxDepth > 0 && keyDepth(prefix, object.Key) > opts.MaxDepth {
+				continue
 			}
 
-			if _, err = a.client.PutObject(ctx, a.bucket, model.Dirname(dirname), strings.NewReader(""), 0, minio.PutObjectOptions{
-				StorageClass: a.storageClass,
-			}); err != nil {
-				return a.ConvertError(fmt.Errorf("create directory: %w", err))
+			item := convertToItem(object)
+
+			if a.ignoreFn != nil {
+				var ignored bool
+
+				for _, prefix := range ignoredPrefixes {
+					if strings.HasPrefix(item.Pathname, prefix) {
+						ignored = true
+
+						break
+					}
+				}
+
+				if ignored {
+					continue
+				}
+
+				if a.ignoreFn(item) {
+					if item.IsDir() {
+						ignoredPrefixes = append(ignoredPrefixes, item.Pathname)
+					}
+
+					continue
+				}
+			}
+
+			if !yield(item, nil) {
+				return
 			}
 		}
 	}
-
-	return nil
 }
 
 func (a Service) Rename(ctx context.Context, oldName, newName string) error {
-	if err := model.ValidPath(oldName); err != nil {
+	oldName, err := a.cleanPath(oldName)
+	if err != nil {
 		return err
 	}
 
-	if err := model.ValidPath(newName); err != nil {
+	newName, err = a.cleanPath(newName)
+	if err != nil {
 		return err
 	}
 
 	oldRoot := a.Path(oldName)
 	newRoot := a.Path(newName)
 
-	return a.Walk(ctx, oldRoot, func(item model.Item) error {
-		pathname := a.Path(item.Pathname)
-
-		if item.IsDir() {
-			pathname = model.Dirname(pathname)
+	// The objects are moved as stored, the markers with their content and the directories implied by the keys following them
+	for object, err := range a.namedObjects(ctx, oldRoot, false) {
+		if err != nil {
+			return err
 		}
 
-		_, err := a.client.CopyObject(ctx, minio.CopyDestOptions{
+		pathname := object.Key
+
+		if _, err = a.client.CopyObject(ctx, minio.CopyDestOptions{
 			Bucket: a.bucket,
-			Object: strings.Replace(pathname, oldRoot, newRoot, 1),
+			Object: rebaseKey(pathname, oldRoot, newRoot),
 		}, minio.CopySrcOptions{
 			Bucket: a.bucket,
 			Object: pathname,
-		})
-		if err != nil {
-			return a.ConvertError(err)
+		}); err != nil {
+			return a.pathError("copy object", pathname, err)
 		}
 
 		if err = a.client.RemoveObject(ctx, a.bucket, pathname, minio.RemoveObjectOptions{}); err != nil {
-			return a.ConvertError(fmt.Errorf("delete object `%s`: %w", pathname, err))
+			return a.pathError("delete object", pathname, err)
 		}
+	}
 
-		return nil
-	})
+	return nil
 }
 
 func (a Service) RemoveAll(ctx context.Context, name string) error {
-	if err := model.ValidPath(name); err != nil {
+	name, err := a.cleanPath(name)
+	if err != nil {
 		return err
 	}
 
-	if err := a.Walk(ctx, name, func(item model.Item) error {
-		if err := a.client.RemoveObject(ctx, a.bucket, a.Path(item.Pathname), minio.RemoveObjectOptions{}); err != nil {
-			return a.ConvertError(fmt.Errorf("delete object `%s`: %w", item.Pathname, err))
+	rootKey := a.Path(name)
+
+	var walkErr error
+
+	keys := func(yield func(string) bool) {
+		for object, err := range a.namedObjects(ctx, rootKey, false) {
+			if err != nil {
+				walkErr = err
+				return
+			}
+
+			if !yield(object.Key) {
+				return
+			}
 		}
+	}
 
-		return nil
-	}); err != nil {
-		return err
+	var errs []error
+
+	for removeErr := range a.removeObjects(ctx, keys) {
+		errs = append(errs, a.pathError("delete object", removeErr.ObjectName, removeErr.Err))
 	}
 
-	return a.client.RemoveObject(ctx, a.bucket, a.Path(name), minio.RemoveObjectOptions{})
+	return errors.Join(walkErr, errors.Join(errs...))
 }
 
-func IsNotExist(err error) bool {
-	return strings.Contains(err.Error(), "The specified key does not exist")
-}
+func (a Service) RemoveMany(ctx context.Context, names []string) ([]model.RemoveResult, error) {
+	results := make([]model.RemoveResult, len(names))
+	indexes := make(map[string][]int, len(names))
 
-func (a Service) ConvertError(err error) error {
-	if err == nil {
-		return nil
+	var keys []string
+
+	for index, name := range names {
+		results[index].Name = name
+
+		name, err := a.cleanPath(name)
+		if err != nil {
+			results[index].Err = err
+			continue
+		}
+
+		key := a.Path(name)
+		if _, ok := indexes[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		indexes[key] = append(indexes[key], index)
 	}
 
-	if IsNotExist(err) {
-		return model.ErrNotExist(err)
+	for removeErr := range a.removeObjects(ctx, slices.Values(keys)) {
+		for _, index := range indexes[removeErr.ObjectName] {
+			results[index].Err = a.pathError("delete object", removeErr.ObjectName, removeErr.Err)
+		}
 	}
 
-	return err
+	return results, ctx.Err()
+}
+
+func (a Service) removeObjects(ctx context.Context, keys iter.Seq[string]) iter.Seq[minio.RemoveObjectError] {
+	return func(yield func(minio.RemoveObjectError) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		objectsCh := make(chan minio.ObjectInfo, removeBatchSize)
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			defer close(objectsCh)
+
+			for key := range keys {
+				select {
+				case <-ctx.Done():
+					return
+				case objectsCh <- minio.ObjectInfo{Key: key}:
+				}
+			}
+		}()
+
+		errorsCh := a.client.RemoveObjects(ctx, a.bucket, objectsCh, minio.RemoveObjectsOptions{})
+
+		defer func() {
+			cancel()
+
+			for range errorsCh {
+			}
+
+			<-done
+		}()
+
+		for removeErr := range errorsCh {
+			if !yield(removeErr) {
+				return
+			}
+		}
+	}
 }
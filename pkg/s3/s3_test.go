@@ -0,0 +1,540 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+var directoryModes = map[string]DirectoryMode{
+	"markers":  DirectoryMarkers,
+	"implicit": DirectoryImplicit,
+}
+
+func TestStat(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys    []string
+		name    string
+		wantDir bool
+		wantErr bool
+	}{
+		"file": {
+			[]string{"foo", "foo.txt"},
+			"/foo",
+			false,
+			false,
+		},
+		"directory without slash": {
+			[]string{"foo/a.txt", "foobar.txt"},
+			"/foo",
+			true,
+			false,
+		},
+		"empty marker": {
+			[]string{"empty/"},
+			"/empty",
+			true,
+			false,
+		},
+		"shared prefix": {
+			[]string{"foobar.txt", "foo-bar/a.txt"},
+			"/foo",
+			false,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		for mode, directoryMode := range directoryModes {
+			t.Run(intention+" "+mode, func(t *testing.T) {
+				t.Parallel()
+
+				instance, _ := newLocalS3(t, directoryMode, tc.keys...)
+
+				item, err := instance.Stat(context.Background(), tc.name)
+
+				if (err != nil) != tc.wantErr {
+					t.Fatalf("Stat() = `%v`, want error %t", err, tc.wantErr)
+				}
+
+				if err == nil && item.IsDir() != tc.wantDir {
+					t.Errorf("Stat() = %+v, want directory %t", item, tc.wantDir)
+				}
+
+				if err != nil && !model.IsNotExist(err) {
+					t.Errorf("Stat() = `%v`, want not exist", err)
+				}
+			})
+		}
+	}
+}
+
+func TestAllNamed(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys []string
+		name string
+		want []string
+	}{
+		"directory": {
+			[]string{"foo/", "foo/a.txt", "foo-bar/b.txt", "foobar.txt"},
+			"/foo",
+			[]string{"/foo/", "/foo/a.txt"},
+		},
+		"file": {
+			[]string{"foo", "foo.txt", "foobar/c.txt"},
+			"/foo",
+			[]string{"/foo"},
+		},
+		"empty marker": {
+			[]string{"empty/", "emptyfile.txt"},
+			"/empty/",
+			[]string{"/empty/"},
+		},
+	}
+
+	for intention, tc := range cases {
+		for mode, directoryMode := range directoryModes {
+			t.Run(intention+" "+mode, func(t *testing.T) {
+				t.Parallel()
+
+				instance, _ := newLocalS3(t, directoryMode, tc.keys...)
+
+				var got []string
+
+				for item, err := range instance.All(context.Background(), tc.name, model.WalkOpts{}) {
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					got = append(got, item.Pathname)
+				}
+
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("All() = %v, want %v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys []string
+		name string
+		want []string
+	}{
+		"directory": {
+			[]string{"foo", "foo/", "foo/a.txt", "foo/sub/b.txt", "foo-bar/c.txt", "foobar.txt"},
+			"/foo",
+			[]string{"foo-bar/c.txt", "foobar.txt"},
+		},
+		"file": {
+			[]string{"foo.txt", "foo.txt.bak"},
+			"/foo.txt",
+			[]string{"foo.txt.bak"},
+		},
+		"empty marker": {
+			[]string{"empty/", "emptyfile.txt"},
+			"/empty",
+			[]string{"emptyfile.txt"},
+		},
+	}
+
+	for intention, tc := range cases {
+		for mode, directoryMode := range directoryModes {
+			t.Run(intention+" "+mode, func(t *testing.T) {
+				t.Parallel()
+
+				instance, bucket := newLocalS3(t, directoryMode, tc.keys...)
+
+				if err := instance.RemoveAll(context.Background(), tc.name); err != nil {
+					t.Fatal(err)
+				}
+
+				if got := bucket.Keys(); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("RemoveAll() = %v, want %v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys    []string
+		oldName string
+		newName string
+		want    []string
+	}{
+		"directory": {
+			[]string{"a/", "a/x", "a/sub/y", "ab/x", "a.txt"},
+			"/a",
+			"/b",
+			[]string{"a.txt", "ab/x", "b/", "b/sub/y", "b/x"},
+		},
+		"file": {
+			[]string{"a", "ab/x"},
+			"/a",
+			"/c",
+			[]string{"ab/x", "c"},
+		},
+		"empty marker": {
+			[]string{"a/", "ab/"},
+			"/a/",
+			"/b/",
+			[]string{"ab/", "b/"},
+		},
+		"nested name": {
+			[]string{"a/a/x"},
+			"/a/a",
+			"/a/b",
+			[]string{"a/b/x"},
+		},
+	}
+
+	for intention, tc := range cases {
+		for mode, directoryMode := range directoryModes {
+			t.Run(intention+" "+mode, func(t *testing.T) {
+				t.Parallel()
+
+				instance, bucket := newLocalS3(t, directoryMode, tc.keys...)
+
+				if err := instance.Rename(context.Background(), tc.oldName, tc.newName); err != nil {
+					t.Fatal(err)
+				}
+
+				if got := bucket.Keys(); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("Rename() = %v, want %v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestWriteToExclusive(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		size int64
+	}{
+		"known size": {
+			7,
+		},
+		"unknown size": {
+			0,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, bucket := newLocalS3(t, DirectoryMarkers, "foo")
+
+			var multipart atomic.Bool
+
+			bucket.OnRequest(func(r *http.Request) {
+				if r.URL.Query().Has("uploads") {
+					multipart.Store(true)
+				}
+			})
+
+			err := instance.WriteTo(context.Background(), "/foo", strings.NewReader("content"), model.WriteOpts{Mode: model.CreateExclusive, Size: tc.size})
+			if !model.IsExist(err) || model.IsConflict(err) {
+				t.Errorf("WriteTo() = `%v`, want exist", err)
+			}
+
+			if err = instance.WriteTo(context.Background(), "/bar", strings.NewReader("content"), model.WriteOpts{Mode: model.CreateExclusive, Size: tc.size}); err != nil {
+				t.Errorf("WriteTo() = `%v`, want nil", err)
+			}
+
+			if object, _ := bucket.Object("bar"); string(object.content) != "content" {
+				t.Errorf("WriteTo() = `%s`, want `content`", object.content)
+			}
+
+			if multipart.Load() {
+				t.Error("WriteTo() used a multipart upload, want a single request")
+			}
+		})
+	}
+}
+
+func TestWriteToExclusiveTooLarge(t *testing.T) {
+	t.Parallel()
+
+	instance, bucket := newLocalS3(t, DirectoryMarkers)
+
+	err := instance.WriteTo(context.Background(), "/large", bytes.NewReader(make([]byte, defaultBufferSize+1)), model.WriteOpts{Mode: model.CreateExclusive})
+	if !errors.Is(err, ErrSizeRequired) {
+		t.Errorf("WriteTo() = `%v`, want size required", err)
+	}
+
+	if _, ok := bucket.Object("large"); ok {
+		t.Error("WriteTo() created the object, want nothing written")
+	}
+}
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{
+		"Content-Type":      {"text/plain"},
+		"Content-Encoding":  {"gzip"},
+		"Cache-Control":     {"no-cache"},
+		"X-Amz-Meta-Author": {"absto"},
+	}
+
+	cases := map[string]struct {
+		size int
+	}{
+		"rewrite": {
+			10,
+		},
+		"compose": {
+			minComposeSize,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, bucket := newLocalS3(t, DirectoryMarkers)
+			bucket.SetObject("file.txt", localObject{header: header.Clone(), content: bytes.Repeat([]byte("a"), tc.size)})
+
+			if err := instance.WriteTo(context.Background(), "/file.txt", strings.NewReader("tail"), model.WriteOpts{Mode: model.Append, Size: 4}); err != nil {
+				t.Fatal(err)
+			}
+
+			object, _ := bucket.Object("file.txt")
+
+			if len(object.content) != tc.size+4 || !bytes.HasSuffix(object.content, []byte("tail")) {
+				t.Errorf("WriteTo() = %d bytes, want %d ending with the appended content", len(object.content), tc.size+4)
+			}
+
+			for name := range header {
+				if got := object.header.Get(name); got != header.Get(name) {
+					t.Errorf("WriteTo() %s = `%s`, want `%s`", name, got, header.Get(name))
+				}
+			}
+
+			if got := bucket.Keys(); !reflect.DeepEqual(got, []string{"file.txt"}) {
+				t.Errorf("WriteTo() = %v, want the appended part removed", got)
+			}
+		})
+	}
+}
+
+func TestAppendConflict(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		replace func(*http.Request) bool
+		content []byte
+	}{
+		"rewrite": {
+			func(r *http.Request) bool {
+				return r.Method == http.MethodPut
+			},
+			[]byte("content"),
+		},
+		"compose": {
+			func(r *http.Request) bool {
+				return r.Method == http.MethodPost && r.URL.Query().Has("uploadId")
+			},
+			bytes.Repeat([]byte("a"), minComposeSize),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, bucket := newLocalS3(t, DirectoryMarkers)
+			bucket.SetObject("file.txt", localObject{content: tc.content})
+
+			var once sync.Once
+
+			// Another writer replaces the object between its read and its replacement
+			bucket.OnRequest(func(r *http.Request) {
+				if tc.replace(r) {
+					once.Do(func() { bucket.SetObject("file.txt", localObject{content: []byte("changed")}) })
+				}
+			})
+
+			err := instance.WriteTo(context.Background(), "/file.txt", strings.NewReader("tail"), model.WriteOpts{Mode: model.Append, Size: 4})
+			if !model.IsConflict(err) || model.IsExist(err) {
+				t.Errorf("WriteTo() = `%v`, want conflict", err)
+			}
+
+			if object, _ := bucket.Object("file.txt"); string(object.content) != "changed" {
+				t.Errorf("WriteTo() = %d bytes, want the concurrent write kept", len(object.content))
+			}
+		})
+	}
+}
+
+func TestAppendPartHidden(t *testing.T) {
+	t.Parallel()
+
+	instance, _ := newLocalS3(t, DirectoryMarkers, "dir/", "dir/file.txt", "dir/file.txt.absto-append-abc")
+
+	items, err := instance.List(context.Background(), "/dir/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].Pathname != "/dir/file.txt" {
+		t.Errorf("List() = %+v, want the file only", items)
+	}
+
+	var got []string
+
+	for item, err := range instance.All(context.Background(), "/dir", model.WalkOpts{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, item.Pathname)
+	}
+
+	if want := []string{"/dir/", "/dir/file.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+}
+
+func TestResume(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		replaced bool
+		want     string
+		wantErr  error
+	}{
+		"same object": {
+			false,
+			"content",
+			nil,
+		},
+		"replaced object": {
+			true,
+			"",
+			model.ErrConflict,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, bucket := newLocalS3(t, DirectoryMarkers)
+			bucket.SetObject("file.txt", localObject{content: []byte("content")})
+
+			reader, err := instance.ReadFrom(context.Background(), "/file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer func() { _ = reader.Close() }()
+
+			if _, err = reader.Read(make([]byte, 1)); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.replaced {
+				bucket.SetObject("file.txt", localObject{content: []byte("changed")})
+			}
+
+			resumed, err := reader.(model.ResumableReader).Resume(context.Background())
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Resume() = `%v`, want `%v`", err, tc.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			defer func() { _ = resumed.Close() }()
+
+			if content, err := io.ReadAll(resumed); err != nil || string(content) != tc.want {
+				t.Errorf("Resume() = `%s`, `%v`, want `%s`", content, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	instance, _ := newLocalS3(t, DirectoryMarkers, "a/b/", "a/b/c.txt", "d/e/", "x.txt")
+
+	got, err := instance.Usage(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Files != 2 || got.Directories != 4 {
+		t.Errorf("Usage() = (%d files, %d directories), want (2, 4)", got.Files, got.Directories)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options []ConfigOption
+		want    model.Capabilities
+	}{
+		"default": {
+			nil,
+			model.Capabilities{
+				MaxObjectSize:  maxObjectSize,
+				RangeReads:     true,
+				ServerSideCopy: true,
+				SignedURL:      true,
+			},
+		},
+		"versioning": {
+			[]ConfigOption{WithVersioning()},
+			model.Capabilities{
+				MaxObjectSize:  maxObjectSize,
+				RangeReads:     true,
+				ServerSideCopy: true,
+				Versioning:     true,
+				SignedURL:      true,
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := New("localhost:9000", "access", "secret", "bucket", false, 0, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := instance.Capabilities(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Capabilities() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var localModTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// localS3 is a local stand-in of a bucket, serving the subset of the S3 API used by the service.
+type localS3 struct {
+	objects   map[string]localObject
+	uploads   map[string]localUpload
+	onRequest func(*http.Request)
+	mutex     sync.Mutex
+}
+
+// localUpload is a pending multipart upload, with the headers of its initiation.
+type localUpload struct {
+	header http.Header
+	parts  map[int][]byte
+}
+
+// localObject is a stored object with its content headers and its user metadata.
+type localObject struct {
+	header  http.Header
+	content []byte
+}
+
+func (o localObject) etag() string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(o.content))
+}
+
+// storedHeaders are the request headers kept with an object and returned when reading it.
+var storedHeaders = []string{"Content-Type", "Content-Encoding", "Cache-Control"}
+
+type listResult struct {
+	XMLName               xml.Name     `xml:"ListBucketResult"`
+	Name                  string       `xml:"Name"`
+	Prefix                string       `xml:"Prefix"`
+	NextContinuationToken string       `xml:"NextContinuationToken,omitempty"`
+	Contents              []listObject `xml:"Contents"`
+	CommonPrefixes        []listPrefix `xml:"CommonPrefixes"`
+	KeyCount              int          `xml:"KeyCount"`
+	MaxKeys               int          `xml:"MaxKeys"`
+	IsTruncated           bool         `xml:"IsTruncated"`
+}
+
+type listObject struct {
+	LastModified time.Time `xml:"LastModified"`
+	Key          string    `xml:"Key"`
+	ETag         string    `xml:"ETag"`
+	Size         int       `xml:"Size"`
+}
+
+type listPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type deleteRequest struct {
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name `xml:"DeleteResult"`
+	Deleted []struct {
+		Key string `xml:"Key"`
+	} `xml:"Deleted"`
+}
+
+// newLocalS3 starts a bucket with the given keys, an empty content being stored for each of them.
+func newLocalS3(t *testing.T, directoryMode DirectoryMode, keys ...string) (Service, *localS3) {
+	t.Helper()
+
+	bucket := &localS3{objects: make(map[string]localObject), uploads: make(map[string]localUpload)}
+
+	for _, key := range keys {
+		bucket.objects[key] = localObject{header: make(http.Header)}
+	}
+
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+
+	instance, err := New(strings.TrimPrefix(server.URL, "http://"), "access", "secret", "bucket", false, 0, WithRegion("us-east-1"), WithDirectoryMode(directoryMode))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return instance, bucket
+}
+
+func (b *localS3) Object(key string) (localObject, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	object, ok := b.objects[key]
+
+	return object, ok
+}
+
+// SetObject stores the object, as written by another client of the bucket.
+func (b *localS3) SetObject(key string, object localObject) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.objects[key] = object
+}
+
+// OnRequest registers a hook called before serving each request, outside of the lock of the bucket.
+func (b *localS3) OnRequest(onRequest func(*http.Request)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.onRequest = onRequest
+}
+
+func (b *localS3) Keys() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return slices.Sorted(func(yield func(string) bool) {
+		for key := range b.objects {
+			if !yield(key) {
+				return
+			}
+		}
+	})
+}
+
+func (b *localS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mutex.Lock()
+	onRequest := b.onRequest
+	b.mutex.Unlock()
+
+	if onRequest != nil {
+		onRequest(r)
+	}
+
+	// The body is read before locking, a client streaming it from another object of the bucket
+	body, err := io.ReadAll(requestBody(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	r.Header.Del("X-Amz-Content-Sha256")
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	switch {
+	case len(key) == 0 && r.Method == http.MethodGet && query.Has("location"):
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+			Value   string   `xml:",chardata"`
+		}{Value: "us-east-1"})
+	case len(key) == 0 && r.Method == http.MethodGet:
+		b.list(w, query)
+	case len(key) == 0 && r.Method == http.MethodPost && query.Has("delete"):
+		b.deleteMany(w, r)
+	case query.Has("uploads") && r.Method == http.MethodPost:
+		uploadID := strconv.Itoa(len(b.uploads) + 1)
+		b.uploads[uploadID] = localUpload{header: requestHeader(r), parts: make(map[int][]byte)}
+
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string   `xml:"Bucket"`
+			Key      string   `xml:"Key"`
+			UploadID string   `xml:"UploadId"`
+		}{Bucket: "bucket", Key: key, UploadID: uploadID})
+	case query.Has("uploadId"):
+		b.upload(w, r, key, query)
+	case r.Method == http.MethodHead, r.Method == http.MethodGet:
+		object, ok := b.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				writeError(w, http.StatusNotFound, "NoSuchKey")
+			}
+
+			return
+		}
+
+		if match := r.Header.Get("If-Match"); len(match) != 0 && match != object.etag() {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		for name, values := range object.header {
+			w.Header()[name] = values
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.content)))
+		w.Header().Set("ETag", object.etag())
+		w.Header().Set("Last-Modified", localModTime.Format(http.TimeFormat))
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.content)
+		}
+	case r.Method == http.MethodPut:
+		b.put(w, r, key)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (b *localS3) list(w http.ResponseWriter, query url.Values) {
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+
+	after := max(query.Get("start-after"), query.Get("continuation-token"))
+
+	maxKeys := 1000
+	if value, err := strconv.Atoi(query.Get("max-keys")); err == nil && value > 0 {
+		maxKeys = value
+	}
+
+	result := listResult{Name: "bucket", Prefix: prefix, MaxKeys: maxKeys}
+
+	var last string
+
+	for _, key := range slices.Sorted(func(yield func(string) bool) {
+		for key := range b.objects {
+			if !yield(key) {
+				return
+			}
+		}
+	}) {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+
+		entry := key
+
+		if index := strings.Index(key[len(prefix):], delimiter); len(delimiter) != 0 && index != -1 {
+			entry = key[:len(prefix)+index+len(delimiter)]
+
+			if entry == last || strings.HasPrefix(after, entry) {
+				continue
+			}
+		}
+
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+
+			break
+		}
+
+		if entry == key {
+			result.Contents = append(result.Contents, listObject{Key: key, LastModified: localModTime, ETag: b.objects[key].etag(), Size: len(b.objects[key].content)})
+		} else {
+			result.CommonPrefixes = append(result.CommonPrefixes, listPrefix{Prefix: entry})
+		}
+
+		result.KeyCount++
+		last = entry
+	}
+
+	writeXML(w, result)
+}
+
+func (b *localS3) put(w http.ResponseWriter, r *http.Request, key string) {
+	existing, exists := b.objects[key]
+
+	if match := r.Header.Get("If-Match"); len(match) != 0 && (!exists || match != existing.etag()) {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	if r.Header.Get("If-None-Match") == "*" && exists {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	if source := r.Header.Get("X-Amz-Copy-Source"); len(source) != 0 {
+		source, _ = url.PathUnescape(source)
+		_, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+		object, ok := b.objects[sourceKey]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+			object.header = requestHeader(r)
+		}
+
+		b.objects[key] = object
+
+		writeXML(w, struct {
+			XMLName      xml.Name  `xml:"CopyObjectResult"`
+			LastModified time.Time `xml:"LastModified"`
+			ETag         string    `xml:"ETag"`
+		}{LastModified: localModTime, ETag: object.etag()})
+
+		return
+	}
+
+	content, err := io.ReadAll(requestBody(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	object := localObject{header: requestHeader(r), content: content}
+	b.objects[key] = object
+
+	w.Header().Set("ETag", object.etag())
+}
+
+func (b *localS3) upload(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	upload, ok := b.uploads[query.Get("uploadId")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+
+		source := r.Header.Get("X-Amz-Copy-Source")
+		if len(source) == 0 {
+			content, err := io.ReadAll(requestBody(r))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "IncompleteBody")
+				return
+			}
+
+			upload.parts[partNumber] = content
+			w.Header().Set("ETag", localObject{content: content}.etag())
+
+			return
+		}
+
+		source, _ = url.PathUnescape(source)
+		_, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+		object, ok := b.objects[sourceKey]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); len(match) != 0 && strings.Trim(match, `"`) != strings.Trim(object.etag(), `"`) {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		content := object.content
+
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("X-Amz-Copy-Source-Range"), "bytes=%d-%d", &start, &end); err == nil {
+			content = content[start : end+1]
+		}
+
+		upload.parts[partNumber] = content
+
+		writeXML(w, struct {
+			XMLName      xml.Name  `xml:"CopyPartResult"`
+			LastModified time.Time `xml:"LastModified"`
+			ETag         string    `xml:"ETag"`
+		}{LastModified: localModTime, ETag: localObject{content: content}.etag()})
+	case http.MethodPost:
+		if match := r.Header.Get("If-Match"); len(match) != 0 && match != b.objects[key].etag() {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		var content []byte
+
+		for _, partNumber := range slices.Sorted(func(yield func(int) bool) {
+			for partNumber := range upload.parts {
+				if !yield(partNumber) {
+					return
+				}
+			}
+		}) {
+			content = append(content, upload.parts[partNumber]...)
+		}
+
+		object := localObject{header: upload.header, content: content}
+		b.objects[key] = object
+		delete(b.uploads, query.Get("uploadId"))
+
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string   `xml:"Bucket"`
+			Key     string   `xml:"Key"`
+			ETag    string   `xml:"ETag"`
+		}{Bucket: "bucket", Key: key, ETag: object.etag()})
+	case http.MethodDelete:
+		delete(b.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (b *localS3) deleteMany(w http.ResponseWriter, r *http.Request) {
+	var request deleteRequest
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+
+	var result deleteResult
+
+	for _, object := range request.Objects {
+		delete(b.objects, object.Key)
+
+		result.Deleted = append(result.Deleted, struct {
+			Key string `xml:"Key"`
+		}{Key: object.Key})
+	}
+
+	writeXML(w, result)
+}
+
+func requestBody(r *http.Request) io.Reader {
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return decodeChunks(r.Body)
+	}
+
+	return r.Body
+}
+
+// decodeChunks reads the payload of an aws-chunked body, the signatures of the chunks being ignored.
+func decodeChunks(body io.Reader) io.Reader {
+	reader := bufio.NewReader(body)
+
+	var output bytes.Buffer
+
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return &output
+		}
+
+		sizeValue, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+
+		size, err := strconv.ParseInt(sizeValue, 16, 64)
+		if err != nil || size == 0 {
+			return &output
+		}
+
+		if _, err = io.CopyN(&output, reader, size); err != nil {
+			return &output
+		}
+
+		_, _ = reader.ReadString('\n')
+	}
+}
+
+func requestHeader(r *http.Request) http.Header {
+	header := make(http.Header)
+
+	for _, name := range storedHeaders {
+		// The encoding of a signed stream is not one of the object, as S3 does
+		if value := strings.TrimPrefix(strings.TrimPrefix(r.Header.Get(name), "aws-chunked"), ","); len(value) != 0 {
+			header.Set(name, value)
+		}
+	}
+
+	for name, values := range r.Header {
+		if strings.HasPrefix(name, userMetadataPrefix) {
+			header[name] = values
+		}
+	}
+
+	return header
+}
+
+func writeXML(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
@@ -0,0 +1,76 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+func (a Service) GetTags(ctx context.Context, pathname string) (map[string]string, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := a.client.GetObjectTagging(ctx, a.bucket, a.Path(pathname), minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, a.pathError("get object tagging", pathname, err)
+	}
+
+	if content := output.ToMap(); len(content) != 0 {
+		return content, nil
+	}
+
+	return nil, nil
+}
+
+func (a Service) SetTags(ctx context.Context, pathname string, content map[string]string) error {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	if len(content) == 0 {
+		return a.DeleteTags(ctx, pathname)
+	}
+
+	objectTags, err := toObjectTags(content)
+	if err != nil {
+		return err
+	}
+
+	if err = a.client.PutObjectTagging(ctx, a.bucket, a.Path(pathname), objectTags, minio.PutObjectTaggingOptions{}); err != nil {
+		return a.pathError("put object tagging", pathname, err)
+	}
+
+	return nil
+}
+
+func (a Service) DeleteTags(ctx context.Context, pathname string) error {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.RemoveObjectTagging(ctx, a.bucket, a.Path(pathname), minio.RemoveObjectTaggingOptions{}); err != nil {
+		return a.pathError("remove object tagging", pathname, err)
+	}
+
+	return nil
+}
+
+func toObjectTags(content map[string]string) (*tags.Tags, error) {
+	if err := model.ValidTags(content); err != nil {
+		return nil, err
+	}
+
+	output, err := tags.NewTags(content, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, model.ErrInvalidTags)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestToObjectTags(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make(map[string]string)
+	for i := range model.MaxTagCount + 1 {
+		tooMany[string(rune('a'+i))] = "value"
+	}
+
+	cases := map[string]struct {
+		content map[string]string
+		wantErr error
+	}{
+		"simple": {
+			map[string]string{"retention": "30d", "owner": "team@example.com"},
+			nil,
+		},
+		"empty value": {
+			map[string]string{"archived": ""},
+			nil,
+		},
+		"empty key": {
+			map[string]string{"": "value"},
+			model.ErrInvalidTags,
+		},
+		"invalid character": {
+			map[string]string{"owner": "a&b"},
+			model.ErrInvalidTags,
+		},
+		"key too long": {
+			map[string]string{strings.Repeat("k", model.MaxTagKeyLength+1): "value"},
+			model.ErrInvalidTags,
+		},
+		"value too long": {
+			map[string]string{"key": strings.Repeat("v", model.MaxTagValueLength+1)},
+			model.ErrInvalidTags,
+		},
+		"too many": {
+			tooMany,
+			model.ErrInvalidTags,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := toObjectTags(tc.content)
+
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("toObjectTags() = `%v`, want `%v`", gotErr, tc.wantErr)
+			}
+
+			if tc.wantErr == nil && !reflect.DeepEqual(got.ToMap(), tc.content) {
+				t.Errorf("toObjectTags() = %v, want %v", got.ToMap(), tc.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+func (a Service) core() minio.Core {
+	return minio.Core{Client: a.client}
+}
+
+func (a Service) CreateUpload(ctx context.Context, pathname string, opts model.WriteOpts) (string, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return "", err
+	}
+
+	if err := model.ValidTags(opts.Tags); err != nil {
+		return "", err
+	}
+
+	uploadID, err := a.core().NewMultipartUpload(ctx, a.bucket, a.Path(pathname), a.putOptions(opts))
+	if err != nil {
+		return "", a.pathError("create multipart upload", pathname, err)
+	}
+
+	return uploadID, nil
+}
+
+func (a Service) UploadPart(ctx context.Context, pathname, uploadID string, partNumber int, reader io.Reader, size int64) (model.Part, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return model.Part{}, err
+	}
+
+	if err := model.ValidPart(partNumber, size); err != nil {
+		return model.Part{}, err
+	}
+
+	part, err := a.core().PutObjectPart(ctx, a.bucket, a.Path(pathname), uploadID, partNumber, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return model.Part{}, a.pathError(fmt.Sprintf("put object part %d", partNumber), pathname, err)
+	}
+
+	return convertToPart(part), nil
+}
+
+func (a Service) ListParts(ctx context.Context, pathname, uploadID string) ([]model.Part, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []model.Part
+	var marker int
+
+	for {
+		result, err := a.core().ListObjectParts(ctx, a.bucket, a.Path(pathname), uploadID, marker, 0)
+		if err != nil {
+			return nil, a.pathError("list object parts of", pathname, err)
+		}
+
+		for _, part := range result.ObjectParts {
+			parts = append(parts, convertToPart(part))
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (a Service) CompleteUpload(ctx context.Context, pathname, uploadID string, parts []model.Part) error {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	if len(parts) == 0 {
+		return fmt.Errorf("no part to complete: %w", model.ErrInvalidPart)
+	}
+
+	completeParts := make([]minio.CompletePart, len(parts))
+	for index, part := range parts {
+		completeParts[index] = minio.CompletePart{
+			PartNumber: part.Number,
+			ETag:       part.ETag,
+		}
+	}
+
+	if _, err := a.core().CompleteMultipartUpload(ctx, a.bucket, a.Path(pathname), uploadID, completeParts, minio.PutObjectOptions{}); err != nil {
+		return a.pathError("complete multipart upload", pathname, err)
+	}
+
+	return nil
+}
+
+func (a Service) AbortUpload(ctx context.Context, pathname, uploadID string) error {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	if err := a.core().AbortMultipartUpload(ctx, a.bucket, a.Path(pathname), uploadID); err != nil {
+		return a.pathError("abort multipart upload", pathname, err)
+	}
+
+	return nil
+}
+
+func (a Service) ListUploads(ctx context.Context, pathname string) ([]model.Upload, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	key := a.Path(pathname)
+	uploadsCh := a.client.ListIncompleteUploads(ctx, a.bucket, key, true)
+
+	defer func() {
+		cancel()
+
+		for range uploadsCh {
+		}
+	}()
+
+	var uploads []model.Upload
+
+	for upload := range uploadsCh {
+		if upload.Err != nil {
+			return nil, a.ConvertError(fmt.Errorf("list incomplete uploads: %w", upload.Err))
+		}
+
+		if item, ok := convertToUpload(key, upload); ok {
+			uploads = append(uploads, item)
+		}
+	}
+
+	return uploads, nil
+}
+
+func convertToUpload(key string, upload minio.ObjectMultipartInfo) (model.Upload, bool) {
+	// The keys only sharing the prefix of the name are not its uploads
+	if upload.Key != key && !strings.HasPrefix(upload.Key, dirPrefix(key)) {
+		return model.Upload{}, false
+	}
+
+	return model.Upload{
+		ID:        upload.UploadID,
+		Name:      "/" + upload.Key,
+		Initiated: upload.Initiated,
+	}, true
+}
+
+func convertToPart(part minio.ObjectPart) model.Part {
+	return model.Part{
+		Number: part.PartNumber,
+		ETag:   part.ETag,
+		Size:   part.Size,
+		Date:   part.LastModified,
+	}
+}
@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestConvertToUpload(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		key    string
+		upload minio.ObjectMultipartInfo
+		want   model.Upload
+		wantOk bool
+	}{
+		"exact name": {
+			"dir/file.txt",
+			minio.ObjectMultipartInfo{Key: "dir/file.txt", UploadID: "upload", Initiated: date},
+			model.Upload{ID: "upload", Name: "/dir/file.txt", Initiated: date},
+			true,
+		},
+		"in directory": {
+			"dir",
+			minio.ObjectMultipartInfo{Key: "dir/sub/file.txt", UploadID: "upload", Initiated: date},
+			model.Upload{ID: "upload", Name: "/dir/sub/file.txt", Initiated: date},
+			true,
+		},
+		"in directory with slash": {
+			"dir/",
+			minio.ObjectMultipartInfo{Key: "dir/file.txt", UploadID: "upload", Initiated: date},
+			model.Upload{ID: "upload", Name: "/dir/file.txt", Initiated: date},
+			true,
+		},
+		"root": {
+			"",
+			minio.ObjectMultipartInfo{Key: "file.txt", UploadID: "upload", Initiated: date},
+			model.Upload{ID: "upload", Name: "/file.txt", Initiated: date},
+			true,
+		},
+		"same prefix": {
+			"dir/file.txt",
+			minio.ObjectMultipartInfo{Key: "dir/file.txt.bak", UploadID: "upload", Initiated: date},
+			model.Upload{},
+			false,
+		},
+		"sibling directory": {
+			"dir",
+			minio.ObjectMultipartInfo{Key: "directory/file.txt", UploadID: "upload", Initiated: date},
+			model.Upload{},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotOk := convertToUpload(tc.key, tc.upload)
+
+			if gotOk != tc.wantOk || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("convertToUpload() = (%+v, %t), want (%+v, %t)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
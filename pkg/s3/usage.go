@@ -0,0 +1,50 @@
+package s3
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/ViBiOh/absto/pkg/model"
+)
+
+func (a Service) Usage(ctx context.Context, name string) (model.Usage, error) {
+	name, err := a.cleanPath(name)
+	if err != nil {
+		return model.Usage{}, err
+	}
+
+	root := model.Dirname("/" + a.Path(name))
+	usage := model.NewUsage()
+
+	// Directories are implicit in object storage, they are deduced from the markers and the prefixes of the keys. A
+	// recorded directory has all its ancestors recorded too.
+	directories := make(map[string]struct{})
+
+	for item, err := range a.All(ctx, name, model.WalkOpts{}) {
+		if err != nil {
+			return model.Usage{}, err
+		}
+
+		dirname := path.Dir(item.Pathname)
+		if item.IsDir() {
+			dirname = strings.TrimSuffix(item.Pathname, "/")
+		}
+
+		for ; len(dirname) > len(root); dirname = path.Dir(dirname) {
+			if _, ok := directories[dirname]; ok {
+				break
+			}
+
+			directories[dirname] = struct{}{}
+		}
+
+		if !item.IsDir() {
+			usage.AddFile(model.UsageChild(root, item.Pathname), item)
+		}
+	}
+
+	usage.Directories = int64(len(directories))
+
+	return usage, nil
+}
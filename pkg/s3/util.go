@@ -2,12 +2,21 @@ package s3
 
 import (
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ViBiOh/absto/pkg/model"
 	"github.com/minio/minio-go/v7"
 )
 
+const (
+	userMetadataPrefix = "X-Amz-Meta-"
+
+	// appendPartInfix names the content staged next to an object during an append, never listed.
+	appendPartInfix = ".absto-append-"
+)
+
 func convertToItem(info minio.ObjectInfo) model.Item {
 	name := path.Base(info.Key)
 	pathname := "/" + info.Key
@@ -24,9 +33,74 @@ func convertToItem(info minio.ObjectInfo) model.Item {
 		item.Extension = strings.ToLower(path.Ext(name))
 		item.SizeValue = info.Size
 		item.FileMode = model.RegularFilePerm
+		item.ContentType = info.ContentType
+		item.ContentEncoding = info.ContentEncoding
+		item.CacheControl = info.Metadata.Get("Cache-Control")
+		item.Metadata = getUserMetadata(info)
 	} else {
 		item.FileMode = model.DirectoryPerm
 	}
 
 	return item
 }
+
+func getUserMetadata(info minio.ObjectInfo) map[string]string {
+	if len(info.UserMetadataStripped) != 0 {
+		return info.UserMetadataStripped
+	}
+
+	var output map[string]string
+
+	for key, values := range info.Metadata {
+		if name, ok := strings.CutPrefix(key, userMetadataPrefix); ok && len(values) != 0 {
+			if output == nil {
+				output = make(map[string]string)
+			}
+
+			output[name] = values[0]
+		}
+	}
+
+	return output
+}
+
+func (a Service) cleanPath(pathname string) (string, error) {
+	return a.pathPolicy.Clean(pathname)
+}
+
+// keyDepth returns the depth of the key below the prefix, zero for the prefix itself.
+func keyDepth(prefix, key string) int {
+	relative := strings.Trim(strings.TrimPrefix(key, strings.TrimSuffix(prefix, "/")), "/")
+	if len(relative) == 0 {
+		return 0
+	}
+
+	return strings.Count(relative, "/") + 1
+}
+
+// dirPrefix returns the prefix of the content of the key as a directory, the root having an empty one.
+func dirPrefix(key string) string {
+	if len(key) == 0 || strings.HasSuffix(key, "/") {
+		return key
+	}
+
+	return key + "/"
+}
+
+// rebaseKey moves the key, being the old key or under it as a directory, to the new key.
+func rebaseKey(key, oldKey, newKey string) string {
+	relative := strings.TrimPrefix(key, strings.TrimSuffix(oldKey, "/"))
+	if len(oldKey) == 0 {
+		relative = "/" + relative
+	}
+
+	return strings.TrimPrefix(strings.TrimSuffix(newKey, "/")+relative, "/")
+}
+
+func appendPartKey(key string, now time.Time) string {
+	return key + appendPartInfix + strconv.FormatInt(now.UnixNano(), 36)
+}
+
+func isAppendPart(key string) bool {
+	return strings.Contains(path.Base(key), appendPartInfix)
+}
@@ -0,0 +1,91 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+func (a Service) ListVersions(ctx context.Context, pathname string) ([]model.Version, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	key := a.Path(pathname)
+
+	var versions []model.Version
+
+	for object, err := range a.listObjects(ctx, minio.ListObjectsOptions{
+		Prefix:       key,
+		WithVersions: true,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+
+		if version, ok := convertToVersion(key, object); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, model.ErrNotExist(fmt.Errorf("no version for `%s`", pathname))
+	}
+
+	return versions, nil
+}
+
+func (a Service) ReadVersion(ctx context.Context, pathname, versionID string) (model.ReadAtSeekCloser, error) {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := a.client.GetObject(ctx, a.bucket, a.Path(pathname), minio.GetObjectOptions{VersionID: versionID})
+	if err != nil {
+		return nil, a.pathError("get object version "+versionID, pathname, err)
+	}
+
+	return object, nil
+}
+
+func (a Service) RestoreVersion(ctx context.Context, pathname, versionID string) error {
+	pathname, err := a.cleanPath(pathname)
+	if err != nil {
+		return err
+	}
+
+	key := a.Path(pathname)
+
+	// Copying a prior version over the object creates a new latest version with its content, keeping the history intact
+	if _, err := a.client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket: a.bucket,
+		Object: key,
+	}, minio.CopySrcOptions{
+		Bucket:    a.bucket,
+		Object:    key,
+		VersionID: versionID,
+	}); err != nil {
+		return a.pathError("restore object version "+versionID, pathname, err)
+	}
+
+	return nil
+}
+
+func convertToVersion(key string, object minio.ObjectInfo) (model.Version, bool) {
+	// Listing by prefix also returns the keys starting with the same characters
+	if object.Key != key {
+		return model.Version{}, false
+	}
+
+	return model.Version{
+		ID:             object.VersionID,
+		Date:           object.LastModified,
+		Size:           object.Size,
+		IsLatest:       object.IsLatest,
+		IsDeleteMarker: object.IsDeleteMarker,
+	}, true
+}
@@ -0,0 +1,66 @@
+package s3
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestConvertToVersion(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		key    string
+		object minio.ObjectInfo
+		want   model.Version
+		wantOk bool
+	}{
+		"latest": {
+			"dir/file.txt",
+			minio.ObjectInfo{Key: "dir/file.txt", VersionID: "v2", LastModified: date, Size: 42, IsLatest: true},
+			model.Version{ID: "v2", Date: date, Size: 42, IsLatest: true},
+			true,
+		},
+		"prior": {
+			"dir/file.txt",
+			minio.ObjectInfo{Key: "dir/file.txt", VersionID: "v1", LastModified: date, Size: 12},
+			model.Version{ID: "v1", Date: date, Size: 12},
+			true,
+		},
+		"delete marker": {
+			"dir/file.txt",
+			minio.ObjectInfo{Key: "dir/file.txt", VersionID: "v3", LastModified: date, IsLatest: true, IsDeleteMarker: true},
+			model.Version{ID: "v3", Date: date, IsLatest: true, IsDeleteMarker: true},
+			true,
+		},
+		"same prefix": {
+			"dir/file.txt",
+			minio.ObjectInfo{Key: "dir/file.txt.bak", VersionID: "v1", LastModified: date, Size: 12, IsLatest: true},
+			model.Version{},
+			false,
+		},
+		"child": {
+			"dir",
+			minio.ObjectInfo{Key: "dir/file.txt", VersionID: "v1", LastModified: date, Size: 12, IsLatest: true},
+			model.Version{},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotOk := convertToVersion(tc.key, tc.object)
+
+			if gotOk != tc.wantOk || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("convertToVersion() = (%+v, %t), want (%+v, %t)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
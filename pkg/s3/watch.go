@@ -0,0 +1,117 @@
+package s3
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+var watchedEvents = []string{
+	string(notification.ObjectCreatedAll),
+	string(notification.ObjectRemovedAll),
+}
+
+func (a Service) Watch(ctx context.Context, pathname string) <-chan model.Event {
+	output := make(chan model.Event)
+
+	go func() {
+		defer close(output)
+
+		pathname, err := a.cleanPath(pathname)
+		if err != nil {
+			model.SendEvent(ctx, output, model.Event{Err: err})
+			return
+		}
+
+		if !a.listen(ctx, pathname, output) {
+			return
+		}
+
+		model.Poll(ctx, a.pollInterval, func(ctx context.Context, walkFn func(model.Item) error) error {
+			return a.Walk(ctx, pathname, walkFn)
+		}, output)
+	}()
+
+	return output
+}
+
+// listen forwards the bucket notifications, only available on MinIO servers. It returns true when notifications stop while the context is still active, meaning polling should take over.
+func (a Service) listen(ctx context.Context, pathname string, output chan<- model.Event) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	prefix := a.Path(pathname)
+
+	notificationsCh := a.client.ListenBucketNotification(ctx, a.bucket, prefix, "", watchedEvents)
+
+	defer func() {
+		cancel()
+
+		for range notificationsCh {
+		}
+	}()
+
+	for info := range notificationsCh {
+		if info.Err != nil {
+			break
+		}
+
+		for _, record := range info.Records {
+			event, ok := a.convertNotification(prefix, record)
+			if !ok {
+				continue
+			}
+
+			if !model.SendEvent(ctx, output, event) {
+				return false
+			}
+		}
+	}
+
+	return ctx.Err() == nil
+}
+
+// convertNotification converts the record of the watched prefix, the server matching the prefix as a raw string,
+// `/foo` also notifying `/foobar`.
+func (a Service) convertNotification(prefix string, record notification.Event) (model.Event, bool) {
+	key, err := url.QueryUnescape(record.S3.Object.Key)
+	if err != nil {
+		key = record.S3.Object.Key
+	}
+
+	if len(prefix) != 0 && key != prefix && !strings.HasPrefix(key, dirPrefix(prefix)) || isAppendPart(key) {
+		return model.Event{}, false
+	}
+
+	var eventType model.EventType
+
+	switch {
+	case strings.HasPrefix(record.EventName, "s3:ObjectCreated:"):
+		eventType = model.Created
+	case strings.HasPrefix(record.EventName, "s3:ObjectRemoved:"):
+		eventType = model.Removed
+	default:
+		return model.Event{}, false
+	}
+
+	lastModified, _ := time.Parse(time.RFC3339Nano, record.EventTime)
+
+	item := convertToItem(minio.ObjectInfo{
+		Key:          key,
+		Size:         record.S3.Object.Size,
+		ETag:         record.S3.Object.ETag,
+		ContentType:  record.S3.Object.ContentType,
+		LastModified: lastModified,
+	})
+
+	if a.ignoreFn != nil && a.ignoreFn(item) {
+		return model.Event{}, false
+	}
+
+	return model.Event{Type: eventType, Item: item}, true
+}
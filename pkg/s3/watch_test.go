@@ -0,0 +1,106 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/absto/pkg/model"
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+func TestConvertNotification(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		prefix    string
+		eventName string
+		key       string
+	}
+
+	cases := map[string]struct {
+		args     args
+		want     model.EventType
+		wantPath string
+		wantOk   bool
+	}{
+		"created": {
+			args{
+				prefix:    "dir",
+				eventName: "s3:ObjectCreated:Put",
+				key:       "dir/file.txt",
+			},
+			model.Created,
+			"/dir/file.txt",
+			true,
+		},
+		"removed": {
+			args{
+				prefix:    "dir/file.txt",
+				eventName: "s3:ObjectRemoved:Delete",
+				key:       "dir/file.txt",
+			},
+			model.Removed,
+			"/dir/file.txt",
+			true,
+		},
+		"escaped": {
+			args{
+				prefix:    "",
+				eventName: "s3:ObjectCreated:Put",
+				key:       "dir/my+file%281%29.txt",
+			},
+			model.Created,
+			"/dir/my file(1).txt",
+			true,
+		},
+		"sibling": {
+			args{
+				prefix:    "dir",
+				eventName: "s3:ObjectCreated:Put",
+				key:       "directory/file.txt",
+			},
+			0,
+			"",
+			false,
+		},
+		"append part": {
+			args{
+				prefix:    "dir",
+				eventName: "s3:ObjectCreated:Put",
+				key:       "dir/file.txt" + appendPartInfix + "1",
+			},
+			0,
+			"",
+			false,
+		},
+		"other event": {
+			args{
+				prefix:    "dir",
+				eventName: "s3:ObjectAccessed:Get",
+				key:       "dir/file.txt",
+			},
+			0,
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var record notification.Event
+			record.EventName = tc.args.eventName
+			record.S3.Object.Key = tc.args.key
+
+			got, gotOk := Service{}.convertNotification(tc.args.prefix, record)
+
+			if gotOk != tc.wantOk {
+				t.Fatalf("convertNotification() = (%+v, %t), want %t", got, gotOk, tc.wantOk)
+			}
+
+			if gotOk && (got.Type != tc.want || got.Item.Pathname != tc.wantPath) {
+				t.Errorf("convertNotification() = (%v, `%s`), want (%v, `%s`)", got.Type, got.Item.Pathname, tc.want, tc.wantPath)
+			}
+		})
+	}
+}
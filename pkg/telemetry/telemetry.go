@@ -3,6 +3,8 @@ package telemetry
 import (
 	"context"
 	"io"
+	"iter"
+	"net/http"
 	"os"
 	"time"
 
@@ -34,6 +36,14 @@ func (a Service) Enabled() bool {
 	return a.storage.Enabled()
 }
 
+func (a Service) Close() error {
+	return a.storage.Close()
+}
+
+func (a Service) Capabilities() model.Capabilities {
+	return a.storage.Capabilities()
+}
+
 func (a Service) Name() string {
 	return a.storage.Name()
 }
@@ -73,6 +83,186 @@ func (a Service) List(ctx context.Context, name string) ([]model.Item, error) {
 	return output, err
 }
 
+func (a Service) ListPage(ctx context.Context, name string, opts model.ListOpts) (model.Page, error) {
+	ctx, span := a.tracer.Start(ctx, "listPage", trace.WithAttributes(attribute.String("name", name), attribute.Int("limit", opts.Limit)))
+	defer span.End()
+
+	output, err := a.storage.ListPage(ctx, name, opts)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) Glob(ctx context.Context, pattern string) ([]model.Item, error) {
+	ctx, span := a.tracer.Start(ctx, "glob", trace.WithAttributes(attribute.String("pattern", pattern)))
+	defer span.End()
+
+	output, err := a.storage.Glob(ctx, pattern)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) Usage(ctx context.Context, name string) (model.Usage, error) {
+	ctx, span := a.tracer.Start(ctx, "usage", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.Usage(ctx, name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) GetTags(ctx context.Context, name string) (map[string]string, error) {
+	ctx, span := a.tracer.Start(ctx, "getTags", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.GetTags(ctx, name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) SetTags(ctx context.Context, name string, tags map[string]string) error {
+	ctx, span := a.tracer.Start(ctx, "setTags", trace.WithAttributes(attribute.String("name", name), attribute.Int("count", len(tags))))
+	defer span.End()
+
+	err := a.storage.SetTags(ctx, name, tags)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (a Service) DeleteTags(ctx context.Context, name string) error {
+	ctx, span := a.tracer.Start(ctx, "deleteTags", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	err := a.storage.DeleteTags(ctx, name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (a Service) ListVersions(ctx context.Context, name string) ([]model.Version, error) {
+	ctx, span := a.tracer.Start(ctx, "listVersions", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.ListVersions(ctx, name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) ReadVersion(ctx context.Context, name, versionID string) (model.ReadAtSeekCloser, error) {
+	ctx, span := a.tracer.Start(ctx, "readVersion", trace.WithAttributes(attribute.String("name", name), attribute.String("version", versionID)))
+	defer span.End()
+
+	output, err := a.storage.ReadVersion(ctx, name, versionID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) RestoreVersion(ctx context.Context, name, versionID string) error {
+	ctx, span := a.tracer.Start(ctx, "restoreVersion", trace.WithAttributes(attribute.String("name", name), attribute.String("version", versionID)))
+	defer span.End()
+
+	err := a.storage.RestoreVersion(ctx, name, versionID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (a Service) CreateUpload(ctx context.Context, name string, opts model.WriteOpts) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "createUpload", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.CreateUpload(ctx, name, opts)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) UploadPart(ctx context.Context, name, uploadID string, partNumber int, reader io.Reader, size int64) (model.Part, error) {
+	ctx, span := a.tracer.Start(ctx, "uploadPart", trace.WithAttributes(attribute.String("name", name), attribute.Int("part", partNumber), attribute.Int64("size", size)))
+	defer span.End()
+
+	output, err := a.storage.UploadPart(ctx, name, uploadID, partNumber, reader, size)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) ListParts(ctx context.Context, name, uploadID string) ([]model.Part, error) {
+	ctx, span := a.tracer.Start(ctx, "listParts", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.ListParts(ctx, name, uploadID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) CompleteUpload(ctx context.Context, name, uploadID string, parts []model.Part) error {
+	ctx, span := a.tracer.Start(ctx, "completeUpload", trace.WithAttributes(attribute.String("name", name), attribute.Int("parts", len(parts))))
+	defer span.End()
+
+	err := a.storage.CompleteUpload(ctx, name, uploadID, parts)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (a Service) AbortUpload(ctx context.Context, name, uploadID string) error {
+	ctx, span := a.tracer.Start(ctx, "abortUpload", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	err := a.storage.AbortUpload(ctx, name, uploadID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (a Service) ListUploads(ctx context.Context, name string) ([]model.Upload, error) {
+	ctx, span := a.tracer.Start(ctx, "listUploads", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	output, err := a.storage.ListUploads(ctx, name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
 func (a Service) WriteTo(ctx context.Context, name string, reader io.Reader, opts model.WriteOpts) error {
 	ctx, span := a.tracer.Start(ctx, "writeTo", trace.WithAttributes(attribute.String("name", name)))
 	defer span.End()
@@ -126,6 +316,63 @@ func (a Service) Walk(ctx context.Context, name string, walkFn func(model.Item)
 	return err
 }
 
+func (a Service) All(ctx context.Context, name string, opts model.WalkOpts) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		ctx, span := a.tracer.Start(ctx, "all", trace.WithAttributes(attribute.String("name", name)))
+		defer span.End()
+
+		for item, err := range a.storage.All(ctx, name, opts) {
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+func (a Service) ListSeq(ctx context.Context, name string) iter.Seq2[model.Item, error] {
+	return func(yield func(model.Item, error) bool) {
+		ctx, span := a.tracer.Start(ctx, "listSeq", trace.WithAttributes(attribute.String("name", name)))
+		defer span.End()
+
+		for item, err := range a.storage.ListSeq(ctx, name) {
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+func (a Service) Watch(ctx context.Context, name string) <-chan model.Event {
+	_, span := a.tracer.Start(ctx, "watch", trace.WithAttributes(attribute.String("name", name)))
+	defer span.End()
+
+	return a.storage.Watch(ctx, name)
+}
+
+func (a Service) SignedURL(ctx context.Context, name, method string, expiry time.Duration) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "signedURL", trace.WithAttributes(attribute.String("name", name), attribute.String("method", method)))
+	defer span.End()
+
+	output, err := a.storage.SignedURL(ctx, name, method, expiry)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return output, err
+}
+
+func (a Service) SignedHandler() http.Handler {
+	return a.storage.SignedHandler()
+}
+
 func (a Service) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
 	ctx, span := a.tracer.Start(ctx, "mkdir", trace.WithAttributes(attribute.String("name", name)))
 	defer span.End()
@@ -162,6 +409,27 @@ func (a Service) RemoveAll(ctx context.Context, name string) error {
 	return err
 }
 
+func (a Service) RemoveMany(ctx context.Context, names []string) ([]model.RemoveResult, error) {
+	ctx, span := a.tracer.Start(ctx, "removeMany", trace.WithAttributes(attribute.Int("count", len(names))))
+	defer span.End()
+
+	results, err := a.storage.RemoveMany(ctx, names)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("failed", failed))
+
+	return results, err
+}
+
 type telemetryCloser struct {
 	model.ReadAtSeekCloser
 	end func(options ...trace.SpanEndOption)